@@ -0,0 +1,136 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/bcallaars/gut/list"
+	"github.com/urfave/cli"
+)
+
+// The flags gut accepts, to tell which ones take a value.
+var appFlags []cli.Flag
+
+// Returns the arguments to run with, the ones in $GUT_DEFAULT_ARGS put in front of the
+// ones given on the command line. Flags given on the command line come later, so they
+// override the defaults.
+func withDefaultArgs(args []string) ([]string, error) {
+	defaults, err := list.SplitArgs(os.Getenv("GUT_DEFAULT_ARGS"))
+
+	if err != nil {
+		return args, fmt.Errorf("GUT_DEFAULT_ARGS: %v", err)
+	} else if len(args) == 0 {
+		return args, nil
+	}
+
+	return append(append([]string{args[0]}, defaults...), args[1:]...), nil
+}
+
+// Moves the flags in front of the paths, as flag parsing stops at the first argument
+// that is not a flag. Without this the flags in gut . -a would be taken for paths.
+// The paths are put after a --, so a path starting with a dash is not taken for a flag
+// either. Single letter flags may be bundled like in ls, gut -la is split into -l -a
+// here before the app parses them.
+func flagsFirst(args []string) []string {
+	known := map[string]bool{}
+	takesValue := map[string]bool{}
+
+	for _, f := range appFlags {
+		_, isBool := f.(cli.BoolFlag)
+
+		for _, name := range strings.Split(f.GetName(), ",") {
+			known[strings.TrimSpace(name)] = true
+			takesValue[strings.TrimSpace(name)] = !isBool
+		}
+	}
+
+	var flags, paths []string
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		if arg == "--" {
+			paths = append(paths, args[i+1:]...)
+			break
+		} else if !strings.HasPrefix(arg, "-") || arg == "-" {
+			paths = append(paths, arg)
+			continue
+		}
+
+		bundle := splitBundle(arg, known)
+		flags = append(flags, bundle...)
+		name := strings.TrimLeft(bundle[len(bundle)-1], "-")
+
+		// The value of the flag is the next argument, unless given as --flag=value
+		if takesValue[name] && i+1 < len(args) {
+			flags = append(flags, args[i+1])
+			i++
+		}
+	}
+
+	if len(paths) == 0 {
+		return flags
+	}
+
+	return append(append(flags, "--"), paths...)
+}
+
+// Returns the single letter flags bundled in the argument, or only the argument when it
+// is a flag of its own or holds a letter that is not a flag. Only the last of the flags
+// can take a value.
+func splitBundle(arg string, known map[string]bool) []string {
+	name := strings.TrimPrefix(arg, "-")
+
+	if strings.HasPrefix(name, "-") || strings.Contains(name, "=") || len(name) < 2 || known[name] {
+		return []string{arg}
+	}
+
+	var bundle []string
+
+	for _, letter := range name {
+		if !known[string(letter)] {
+			return []string{arg}
+		}
+
+		bundle = append(bundle, "-"+string(letter))
+	}
+
+	return bundle
+}
+
+// Returns the long names of the flags given in the arguments, which the app has parsed
+// already.
+func commandLineFlags(args []string) []string {
+	set := flag.NewFlagSet("gut", flag.ContinueOnError)
+	set.SetOutput(ioutil.Discard)
+
+	for _, f := range appFlags {
+		f.Apply(set)
+	}
+
+	set.Parse(flagsFirst(args))
+
+	given := map[string]bool{}
+
+	set.Visit(func(f *flag.Flag) {
+		given[f.Name] = true
+	})
+
+	var names []string
+
+	for _, f := range appFlags {
+		aliases := strings.Split(f.GetName(), ",")
+
+		for _, alias := range aliases {
+			if given[strings.TrimSpace(alias)] {
+				names = append(names, strings.TrimSpace(aliases[0]))
+				break
+			}
+		}
+	}
+
+	return names
+}
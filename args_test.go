@@ -0,0 +1,96 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bcallaars/gut/list"
+)
+
+func TestFlagsFirst(t *testing.T) {
+	appFlags = list.Flags
+
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{nil, nil},
+		{[]string{"."}, []string{"--", "."}},
+		{[]string{"-x", "pattern"}, []string{"-x", "pattern"}},
+		{[]string{"-x", "pattern", "/tmp"}, []string{"-x", "pattern", "--", "/tmp"}},
+		{[]string{"/tmp", "-x", "pattern"}, []string{"-x", "pattern", "--", "/tmp"}},
+		{[]string{".", "-a", "--sort", "time", "/tmp"}, []string{"-a", "--sort", "time", "--", ".", "/tmp"}},
+		{[]string{"--regexp=pattern", "."}, []string{"--regexp=pattern", "--", "."}},
+		{[]string{"-a", "--", "-file", "-l"}, []string{"-a", "--", "-file", "-l"}},
+		{[]string{"-", "-l"}, []string{"-l", "--", "-"}},
+		{[]string{"-x"}, []string{"-x"}},
+		{[]string{".", "-la"}, []string{"-l", "-a", "--", "."}},
+		{[]string{"-ax", "pattern", "."}, []string{"-a", "-x", "pattern", "--", "."}},
+		{[]string{"-lz", "."}, []string{"-lz", "--", "."}},
+	}
+
+	for _, test := range tests {
+		if got := flagsFirst(test.args); strings.Join(got, " ") != strings.Join(test.want, " ") || len(got) != len(test.want) {
+			t.Errorf("flagsFirst(%q) = %q, want %q", test.args, got, test.want)
+		}
+	}
+}
+
+func TestWithDefaultArgs(t *testing.T) {
+	tests := []struct {
+		defaults string
+		args     []string
+		want     []string
+		err      bool
+	}{
+		{"", []string{"gut", "."}, []string{"gut", "."}, false},
+		{"-a --sort time", []string{"gut", "-r", "."}, []string{"gut", "-a", "--sort", "time", "-r", "."}, false},
+		{`--exclude '\.o$'`, []string{"gut"}, []string{"gut", "--exclude", `\.o$`}, false},
+		{"'-a", []string{"gut"}, []string{"gut"}, true},
+	}
+
+	for _, test := range tests {
+		t.Setenv("GUT_DEFAULT_ARGS", test.defaults)
+
+		got, err := withDefaultArgs(test.args)
+
+		if test.err && (err == nil || err.Error() != "GUT_DEFAULT_ARGS: unterminated quote or escape") {
+			t.Errorf("%q: got %v, want the unterminated quote in GUT_DEFAULT_ARGS", test.defaults, err)
+		} else if !test.err && (err != nil || strings.Join(got, " ") != strings.Join(test.want, " ")) {
+			t.Errorf("%q: got %q, %v, want %q", test.defaults, got, err, test.want)
+		}
+	}
+}
+
+func TestCommandLineFlags(t *testing.T) {
+	appFlags = list.Flags
+
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{nil, nil},
+		{[]string{"."}, nil},
+		{[]string{"-a", "."}, []string{"all"}},
+		{[]string{".", "--all", "-r", "--sort", "time"}, []string{"all", "sort", "reverse"}},
+		{[]string{"-x", "-a"}, []string{"regexp"}},
+	}
+
+	for _, test := range tests {
+		got := commandLineFlags(test.args)
+		var want []string
+
+		// Reported in the order of the flags, not of the arguments
+		for _, f := range list.Flags {
+			for _, name := range test.want {
+				if strings.Split(f.GetName(), ",")[0] == name {
+					want = append(want, name)
+				}
+			}
+		}
+
+		if strings.Join(got, " ") != strings.Join(want, " ") {
+			t.Errorf("commandLineFlags(%q) = %q, want %q", test.args, got, want)
+		}
+	}
+}
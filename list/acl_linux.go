@@ -0,0 +1,12 @@
+//go:build linux
+
+package list
+
+import "syscall"
+
+// Returns whether the file has an access ACL beyond what its mode bits describe.
+func hasACL(path string) bool {
+	size, err := syscall.Getxattr(path, "system.posix_acl_access", nil)
+
+	return err == nil && size > 0
+}
@@ -0,0 +1,64 @@
+//go:build linux
+
+package list
+
+import (
+	"encoding/binary"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// Returns a POSIX access ACL in the form the kernel stores it, giving the user with the
+// id read access on top of the mode bits.
+func userACL(uid uint32) []byte {
+	entries := []struct {
+		tag, perm uint16
+		id        uint32
+	}{
+		{0x01, 6, 0xffffffff},
+		{0x02, 4, uid},
+		{0x04, 4, 0xffffffff},
+		{0x10, 4, 0xffffffff},
+		{0x20, 4, 0xffffffff},
+	}
+
+	acl := binary.LittleEndian.AppendUint32(nil, 2)
+
+	for _, entry := range entries {
+		acl = binary.LittleEndian.AppendUint16(acl, entry.tag)
+		acl = binary.LittleEndian.AppendUint16(acl, entry.perm)
+		acl = binary.LittleEndian.AppendUint32(acl, entry.id)
+	}
+
+	return acl
+}
+
+func TestACL(t *testing.T) {
+	dir := fixture(t, map[string]string{"shared": "", "plain": ""})
+
+	if err := syscall.Setxattr(filepath.Join(dir, "shared"), "system.posix_acl_access", userACL(4242), 0); err != nil {
+		t.Skipf("no ACLs on the file system of the temporary directory: %v", err)
+	}
+
+	out := render(t, dir, func(opts *Options) { opts.Long, opts.ACL = true, true })
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"shared", "-rw-r--r--+"},
+		{"plain", "-rw-r--r--"},
+	}
+
+	for _, test := range tests {
+		if fields := fieldsOf(t, out, test.name); fields[0] != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, fields[0], test.want)
+		}
+	}
+
+	if out := render(t, dir, func(opts *Options) { opts.Long = true }); strings.Contains(out, "+") {
+		t.Errorf("got an ACL marker without --acl:\n%s", out)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package list
+
+// POSIX ACLs are only read on Linux, elsewhere no file is reported as having one.
+func hasACL(path string) bool {
+	return false
+}
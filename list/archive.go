@@ -0,0 +1,78 @@
+package list
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"os"
+	"strings"
+)
+
+// Returns the files in a zip or (gzipped) tar archive in the order they are stored, so
+// they can go through the same filtering, sorting and output as a directory listing.
+// The files are named by their full path within the archive so nested files can be
+// told apart.
+func readArchive(path string) ([]os.FileInfo, error) {
+	if strings.HasSuffix(path, ".zip") {
+		return readZip(path)
+	}
+
+	return readTar(path)
+}
+
+func readZip(path string) ([]os.FileInfo, error) {
+	archive, err := zip.OpenReader(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer archive.Close()
+
+	var files []os.FileInfo
+
+	for _, file := range archive.File {
+		files = append(files, namedEntry{file.FileInfo(), strings.TrimSuffix(file.Name, "/")})
+	}
+
+	return files, nil
+}
+
+func readTar(path string) ([]os.FileInfo, error) {
+	archive, err := os.Open(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer archive.Close()
+
+	var stream io.Reader = archive
+
+	if strings.HasSuffix(path, ".gz") || strings.HasSuffix(path, ".tgz") {
+		gzipStream, err := gzip.NewReader(archive)
+
+		if err != nil {
+			return nil, err
+		}
+
+		defer gzipStream.Close()
+		stream = gzipStream
+	}
+
+	reader := tar.NewReader(stream)
+	var files []os.FileInfo
+
+	for {
+		header, err := reader.Next()
+
+		if err == io.EOF {
+			return files, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		files = append(files, namedEntry{header.FileInfo(), strings.TrimSuffix(header.Name, "/")})
+	}
+}
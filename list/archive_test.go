@@ -0,0 +1,133 @@
+package list
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// The entries of the test archives in the order they are stored, with their content.
+var archiveEntries = []struct {
+	name    string
+	content string
+}{
+	{"small", "1"},
+	{"docs/", ""},
+	{"docs/large", "1234567"},
+	{"medium", "123"},
+}
+
+// Writes a zip archive with the archiveEntries and returns its path.
+func writeZip(t testing.TB, dir string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, "test.zip")
+	file, err := os.Create(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer file.Close()
+
+	archive := zip.NewWriter(file)
+
+	for _, entry := range archiveEntries {
+		header := &zip.FileHeader{Name: entry.name, Method: zip.Store, Modified: fixtureTime}
+		writer, err := archive.CreateHeader(header)
+
+		if err == nil {
+			_, err = io.WriteString(writer, entry.content)
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+// Writes a gzipped tar archive with the archiveEntries and returns its path.
+func writeTarGz(t testing.TB, dir string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, "test.tar.gz")
+	file, err := os.Create(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer file.Close()
+
+	compressed := gzip.NewWriter(file)
+	archive := tar.NewWriter(compressed)
+
+	for _, entry := range archiveEntries {
+		header := &tar.Header{Name: entry.name, Mode: 0644, Size: int64(len(entry.content)), ModTime: fixtureTime, Typeflag: tar.TypeReg}
+
+		if strings.HasSuffix(entry.name, "/") {
+			header.Mode, header.Typeflag = 0755, tar.TypeDir
+		}
+
+		err := archive.WriteHeader(header)
+
+		if err == nil {
+			_, err = io.WriteString(archive, entry.content)
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := compressed.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestPeek(t *testing.T) {
+	dir := t.TempDir()
+	archives := []string{writeZip(t, dir), writeTarGz(t, dir)}
+
+	tests := []struct {
+		name string
+		set  func(opts *Options)
+		want string
+	}{
+		{"sorted by name", func(opts *Options) {}, "docs docs/large medium small"},
+		{"sorted by size", func(opts *Options) { opts.Sort = "size" }, "docs/large medium small docs"},
+		{"reversed size", func(opts *Options) { opts.Sort, opts.Reverse = "size", true }, "docs small medium docs/large"},
+		{"stored order", func(opts *Options) { opts.Sort = "none" }, "small docs docs/large medium"},
+		{"filtered", func(opts *Options) { opts.Regexp = "^docs/" }, "docs/large"},
+	}
+
+	for _, archive := range archives {
+		for _, test := range tests {
+			out := render(t, archive, func(opts *Options) {
+				opts.Peek = true
+				test.set(opts)
+			})
+
+			if got := strings.Join(strings.Fields(out), " "); got != test.want {
+				t.Errorf("%s, %s: got %q, want %q", filepath.Base(archive), test.name, got, test.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,49 @@
+//go:build linux
+
+package list
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// The ioctl and flags of chattr, from linux/fs.h.
+const (
+	fsImmutableFlag = 0x10
+	fsAppendFlag    = 0x20
+)
+
+// _IOR('f', 1, long), the size of a long depending on the architecture
+var fsIocGetFlags = uintptr(2<<30 | unsafe.Sizeof(uintptr(0))<<16 | 'f'<<8 | 1)
+
+// Returns the immutable and append-only attributes set on the file with chattr. Files on
+// a file system without attributes have none.
+func fileAttributes(path string) []string {
+	// Non-blocking so opening a named pipe does not wait for a writer
+	fd, err := syscall.Open(path, syscall.O_RDONLY|syscall.O_NONBLOCK|syscall.O_NOFOLLOW, 0)
+
+	if err != nil {
+		return nil
+	}
+
+	defer syscall.Close(fd)
+
+	var flags int32
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), fsIocGetFlags, uintptr(unsafe.Pointer(&flags)))
+
+	if errno != 0 {
+		return nil
+	}
+
+	var attributes []string
+
+	if flags&fsImmutableFlag != 0 {
+		attributes = append(attributes, "immutable")
+	}
+
+	if flags&fsAppendFlag != 0 {
+		attributes = append(attributes, "append-only")
+	}
+
+	return attributes
+}
@@ -0,0 +1,42 @@
+//go:build linux
+
+package list
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAttrs(t *testing.T) {
+	dir := fixture(t, map[string]string{"locked": "", "log": "", "plain": ""})
+
+	for name, flag := range map[string]string{"locked": "+i", "log": "+a"} {
+		path := filepath.Join(dir, name)
+
+		if out, err := exec.Command("chattr", flag, path).CombinedOutput(); err != nil {
+			t.Skipf("can not set attributes with chattr: %v %s", err, out)
+		}
+
+		// The temporary directory can not be removed with an immutable file in it
+		t.Cleanup(func() { exec.Command("chattr", "-i", "-a", path).Run() })
+	}
+
+	out := render(t, dir, func(opts *Options) { opts.Long, opts.Attrs = true, true })
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"locked", "locked [immutable]"},
+		{"log", "log [append-only]"},
+		{"plain", "plain"},
+	}
+
+	for _, test := range tests {
+		if line := lineOf(t, out, test.name); !strings.HasSuffix(line, " "+test.want) {
+			t.Errorf("got %q, want it to end in %q", line, test.want)
+		}
+	}
+}
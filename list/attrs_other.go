@@ -0,0 +1,8 @@
+//go:build !linux
+
+package list
+
+// The chattr attributes only exist on Linux, elsewhere no file has any.
+func fileAttributes(path string) []string {
+	return nil
+}
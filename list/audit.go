@@ -0,0 +1,51 @@
+package list
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"strings"
+)
+
+// Returns the security relevant findings for a file: being writable by anyone, running
+// as its owner or group, or being owned by a user that does not exist. The owner is not
+// looked up with --fast.
+func auditFile(file os.FileInfo, opts Options) []string {
+	var findings []string
+	mode := file.Mode()
+
+	// The permissions of a symlink itself are meaningless
+	if mode&os.ModeSymlink == 0 && mode.Perm()&0002 != 0 {
+		findings = append(findings, "world-writable")
+	}
+
+	if mode&os.ModeSetuid != 0 {
+		findings = append(findings, "setuid")
+	}
+
+	if mode&os.ModeSetgid != 0 {
+		findings = append(findings, "setgid")
+	}
+
+	if uid, _, ok := ownerIDs(file); ok && !opts.Fast {
+		if _, err := user.LookupId(uid); err != nil {
+			findings = append(findings, "no owner")
+		}
+	}
+
+	return findings
+}
+
+func auditCell(findings []string) cell {
+	return cell{{" [" + strings.Join(findings, ", ") + "]", colorPermWrite}}
+}
+
+// Prints how many of the entries have security relevant findings.
+func printAuditSummary(w io.Writer, flagged int, entries int) {
+	if flagged > 0 {
+		colorPermWrite.Fprintf(w, "%d of %d entries flagged by the audit\n", flagged, entries)
+	} else {
+		fmt.Fprintf(w, "0 of %d entries flagged by the audit\n", entries)
+	}
+}
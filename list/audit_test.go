@@ -0,0 +1,75 @@
+package list
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+func TestAudit(t *testing.T) {
+	dir := fixture(t, map[string]string{"open": "", "setuid": "", "setgid": "", "plain": ""})
+	symlink(t, dir, "plain", "link")
+
+	modes := map[string]os.FileMode{"open": 0666, "setuid": 0755 | os.ModeSetuid, "setgid": 0755 | os.ModeSetgid}
+
+	for name, mode := range modes {
+		if err := os.Chmod(filepath.Join(dir, name), mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out := render(t, dir, func(opts *Options) { opts.Long, opts.Audit = true, true })
+	finding := regexp.MustCompile(` (\S+) \[(.*)\]$`)
+	got := map[string]string{}
+
+	for _, line := range lines(out) {
+		if match := finding.FindStringSubmatch(line); match != nil {
+			got[match[1]] = match[2]
+		}
+	}
+
+	want := map[string]string{"open": "world-writable", "setuid": "setuid", "setgid": "setgid"}
+
+	if len(got) != len(want) {
+		t.Errorf("got findings %v, want %v", got, want)
+	}
+
+	for name, findings := range want {
+		if got[name] != findings {
+			t.Errorf("%s: got findings %q, want %q", name, got[name], findings)
+		}
+	}
+
+	if summary := lines(out); summary[len(summary)-1] != "3 of 5 entries flagged by the audit" {
+		t.Errorf("got summary %q", summary[len(summary)-1])
+	}
+}
+
+func TestAuditOwner(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("only root can give a file an owner without a name")
+	}
+
+	dir := fixture(t, map[string]string{"orphan": ""})
+
+	if err := os.Chown(filepath.Join(dir, "orphan"), 4242, 4242); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		fast bool
+		want string
+	}{
+		{false, "1 of 1 entries flagged by the audit"},
+		{true, "0 of 1 entries flagged by the audit"},
+	}
+
+	for _, test := range tests {
+		out := lines(render(t, dir, func(opts *Options) { opts.Long, opts.Audit, opts.Fast = true, true, test.fast }))
+
+		if summary := out[len(out)-1]; summary != test.want {
+			t.Errorf("fast %v: got summary %q, want %q", test.fast, summary, test.want)
+		}
+	}
+}
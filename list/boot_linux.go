@@ -0,0 +1,36 @@
+//go:build linux
+
+package list
+
+import (
+	"errors"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Returns when the system was booted, read from the btime line in /proc/stat.
+func bootTime() (time.Time, error) {
+	stat, err := ioutil.ReadFile("/proc/stat")
+
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	for _, line := range strings.Split(string(stat), "\n") {
+		fields := strings.Fields(line)
+
+		if len(fields) == 2 && fields[0] == "btime" {
+			seconds, err := strconv.ParseInt(fields[1], 10, 64)
+
+			if err != nil {
+				return time.Time{}, err
+			}
+
+			return time.Unix(seconds, 0), nil
+		}
+	}
+
+	return time.Time{}, errors.New("no boot time found in /proc/stat")
+}
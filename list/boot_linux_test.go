@@ -0,0 +1,27 @@
+//go:build linux
+
+package list
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSinceBoot(t *testing.T) {
+	boot, err := bootTime()
+
+	if err != nil {
+		t.Skip(err)
+	} else if time.Since(boot) < 2*time.Second {
+		t.Skip("booted too recently to tell the files apart")
+	}
+
+	dir := fixture(t, map[string]string{"before": "", "after": ""})
+	touch(t, filepath.Join(dir, "before"), boot.Add(-time.Second))
+	touch(t, filepath.Join(dir, "after"), boot.Add(time.Second))
+
+	if out := render(t, dir, func(opts *Options) { opts.SinceBoot = true }); out != "after\n" {
+		t.Errorf("got %q, want only the file modified after the boot", out)
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package list
+
+import (
+	"errors"
+	"time"
+)
+
+// The boot time is only known on Linux.
+func bootTime() (time.Time, error) {
+	return time.Time{}, errors.New("--since-boot is only supported on Linux")
+}
@@ -0,0 +1,452 @@
+package list
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/fatih/color"
+	"golang.org/x/text/width"
+)
+
+// A segment is a piece of text printed in a single colour. A nil colour prints the
+// text as is.
+type segment struct {
+	text  string
+	color *color.Color
+}
+
+// A cell holds the value of one column for one file. It can be made up of several
+// segments when parts of the value are coloured differently, like the permissions.
+type cell []segment
+
+// Returns the text of the cell without any colouring.
+func (c cell) String() string {
+	var text string
+
+	for _, s := range c {
+		text += s.text
+	}
+
+	return text
+}
+
+// Returns the number of terminal columns the cell takes up.
+func (c cell) width() int {
+	return displayWidth(c.String())
+}
+
+// Returns the number of terminal columns a string takes up, counting East Asian wide
+// characters as two columns.
+func displayWidth(str string) int {
+	size := 0
+
+	for _, r := range str {
+		switch width.LookupRune(r).Kind() {
+		case width.EastAsianWide, width.EastAsianFullwidth:
+			size += 2
+		default:
+			size++
+		}
+	}
+
+	return size
+}
+
+// Returns a copy of the cell with every segment printed in the given colour.
+func (c cell) withColor(col *color.Color) cell {
+	recoloured := make(cell, len(c))
+
+	for i, s := range c {
+		recoloured[i] = segment{s.text, col}
+	}
+
+	return recoloured
+}
+
+func (c cell) print(w io.Writer) {
+	for _, s := range c {
+		if s.color == nil {
+			fmt.Fprint(w, s.text)
+		} else {
+			s.color.Fprint(w, s.text)
+		}
+	}
+}
+
+// A column is one field of the listing, holding a cell for every file. Cells are
+// padded to the width of the column, on the left if the column is right aligned.
+type column struct {
+	header     string
+	width      int
+	alignRight bool
+	cells      []cell
+}
+
+func (col *column) add(c cell) {
+	col.cells = append(col.cells, c)
+}
+
+// Returns the width of the widest cell in the column, at least its set width.
+func (col *column) widest() int {
+	widest := col.width
+
+	for _, c := range col.cells {
+		if c.width() > widest {
+			widest = c.width()
+		}
+	}
+
+	return widest
+}
+
+// Widens every column to its widest cell, so a long value does not push the columns
+// after it out of line. The widths set up front only act as the minimum.
+func fitToCells(columns []*column) {
+	for _, col := range columns {
+		col.width = col.widest()
+	}
+}
+
+// Prints the columns row by row, separated by the spacer. The last non-empty cell of
+// a row is not padded, so no line ends in whitespace. A heading for a row is printed on
+// a line of its own above it.
+func printColumns(w io.Writer, columns []*column, spacer string, headings map[int]string) {
+	if len(columns) == 0 {
+		return
+	}
+
+	for row := range columns[0].cells {
+		last := lastFilledColumn(columns, row)
+
+		if heading, ok := headings[row]; ok {
+			colorHidden.Fprintln(w, heading)
+		}
+
+		for i, col := range columns[:last+1] {
+			c := col.cells[row]
+
+			if i == last {
+				c.print(w)
+				break
+			}
+
+			col.print(w, c)
+			fmt.Fprint(w, spacer)
+		}
+
+		fmt.Fprintln(w)
+	}
+}
+
+// Prints the cells in as many columns as fit within the width, filled top to bottom and
+// then left to right like ls does. When even two columns do not fit every cell gets a
+// line of its own. With padNames the first segments are padded within each column.
+func printGrid(w io.Writer, cells []cell, width int, spacer string, padNames bool) {
+	if len(cells) == 0 {
+		return
+	}
+
+	rows := 1
+
+	for ; rows < len(cells); rows++ {
+		if gridRowWidth(gridColumns(cells, rows, padNames), spacer) <= width {
+			break
+		}
+	}
+
+	columns := gridColumns(cells, rows, padNames)
+	widths := gridColumnWidths(columns)
+
+	for row := 0; row < rows; row++ {
+		for col := range columns {
+			if row >= len(columns[col]) {
+				break
+			}
+
+			columns[col][row].print(w)
+
+			// Nothing follows the last cell of a row, so it is not padded
+			if col < len(columns)-1 && row < len(columns[col+1]) {
+				fmt.Fprint(w, strings.Repeat(" ", widths[col]-columns[col][row].width())+spacer)
+			}
+		}
+
+		fmt.Fprintln(w)
+	}
+}
+
+// Returns the cells of each column of a grid with the given number of rows, padded
+// within the column with padNames.
+func gridColumns(cells []cell, rows int, padNames bool) [][]cell {
+	var columns [][]cell
+
+	for start := 0; start < len(cells); start += rows {
+		end := start + rows
+
+		if end > len(cells) {
+			end = len(cells)
+		}
+
+		col := &column{cells: append([]cell{}, cells[start:end]...)}
+
+		if padNames {
+			padFirstSegments(col)
+		}
+
+		columns = append(columns, col.cells)
+	}
+
+	return columns
+}
+
+// Returns the widths of the columns of a grid.
+func gridColumnWidths(columns [][]cell) []int {
+	widths := make([]int, len(columns))
+
+	for i, col := range columns {
+		for _, c := range col {
+			if c.width() > widths[i] {
+				widths[i] = c.width()
+			}
+		}
+	}
+
+	return widths
+}
+
+// Returns how wide the rows of a grid get.
+func gridRowWidth(columns [][]cell, spacer string) int {
+	widths := gridColumnWidths(columns)
+	total := len(spacer) * (len(widths) - 1)
+
+	for _, width := range widths {
+		total += width
+	}
+
+	return total
+}
+
+// Prints a cell padded to the width of the column, on the side it is not aligned to.
+func (col *column) print(w io.Writer, c cell) {
+	padding := ""
+
+	if col.width > c.width() {
+		padding = strings.Repeat(" ", col.width-c.width())
+	}
+
+	if col.alignRight {
+		fmt.Fprint(w, padding)
+		c.print(w)
+	} else {
+		c.print(w)
+		fmt.Fprint(w, padding)
+	}
+}
+
+// Prints the columns framed in box-drawing lines, a line between every two columns and
+// with --header a line under the names of the columns. The colours stay within the
+// cells, so the frame itself is never coloured.
+func printBordered(w io.Writer, columns []*column, header bool) {
+	if len(columns) == 0 {
+		return
+	}
+
+	if header {
+		for _, col := range columns {
+			if displayWidth(col.header) > col.width {
+				col.width = displayWidth(col.header)
+			}
+		}
+	}
+
+	printRule(w, columns, "┌", "┬", "┐")
+
+	if header {
+		fmt.Fprint(w, "│")
+
+		for _, col := range columns {
+			fmt.Fprint(w, " ")
+			col.print(w, cell{{col.header, colorHeader}})
+			fmt.Fprint(w, " │")
+		}
+
+		fmt.Fprintln(w)
+		printRule(w, columns, "├", "┼", "┤")
+	}
+
+	for row := range columns[0].cells {
+		fmt.Fprint(w, "│")
+
+		for _, col := range columns {
+			fmt.Fprint(w, " ")
+			col.print(w, col.cells[row])
+			fmt.Fprint(w, " │")
+		}
+
+		fmt.Fprintln(w)
+	}
+
+	printRule(w, columns, "└", "┴", "┘")
+}
+
+// Prints a horizontal line of the frame across all columns, with the given corners and
+// the joint where it meets the lines between the columns.
+func printRule(w io.Writer, columns []*column, left string, joint string, right string) {
+	parts := make([]string, len(columns))
+
+	for i, col := range columns {
+		parts[i] = strings.Repeat("─", col.width+2)
+	}
+
+	fmt.Fprintln(w, left+strings.Join(parts, joint)+right)
+}
+
+// Prints the columns as a GitHub flavoured markdown table without any colours, padding
+// the cells so the table also lines up as plain text.
+func printMarkdown(w io.Writer, columns []*column) {
+	rows := [][]string{{}, {}}
+	widths := make([]int, len(columns))
+
+	for i, col := range columns {
+		rows[0] = append(rows[0], col.header)
+		widths[i] = displayWidth(col.header)
+
+		if widths[i] < 4 {
+			widths[i] = 4
+		}
+
+		for row, c := range col.cells {
+			if len(rows) < row+3 {
+				rows = append(rows, []string{})
+			}
+
+			value := strings.Replace(c.String(), "|", "\\|", -1)
+			rows[row+2] = append(rows[row+2], value)
+
+			if displayWidth(value) > widths[i] {
+				widths[i] = displayWidth(value)
+			}
+		}
+	}
+
+	for i, col := range columns {
+		if col.alignRight {
+			rows[1] = append(rows[1], strings.Repeat("-", widths[i]-1)+":")
+		} else {
+			rows[1] = append(rows[1], strings.Repeat("-", widths[i]))
+		}
+	}
+
+	for _, row := range rows {
+		for i, value := range row {
+			padding := strings.Repeat(" ", widths[i]-displayWidth(value))
+
+			if columns[i].alignRight {
+				row[i] = padding + value
+			} else {
+				row[i] = value + padding
+			}
+		}
+
+		fmt.Fprintln(w, "| "+strings.Join(row, " | ")+" |")
+	}
+}
+
+// Returns the index of the last column that has a non-blank cell in the given row.
+func lastFilledColumn(columns []*column, row int) int {
+	for i := len(columns) - 1; i > 0; i-- {
+		if strings.TrimSpace(columns[i].cells[row].String()) != "" {
+			return i
+		}
+	}
+
+	return 0
+}
+
+// Pads the first segment of every cell in the column, usually the file name, to the
+// widest one so whatever follows it lines up. Cells without anything following the
+// first segment are left alone to not leave trailing whitespace.
+func padFirstSegments(col *column) {
+	widest := 0
+
+	for _, c := range col.cells {
+		if len(c) > 0 && displayWidth(c[0].text) > widest {
+			widest = displayWidth(c[0].text)
+		}
+	}
+
+	for i, c := range col.cells {
+		if len(c) < 2 {
+			continue
+		}
+
+		padding := segment{strings.Repeat(" ", widest-displayWidth(c[0].text)), nil}
+		col.cells[i] = append(cell{c[0], padding}, c[1:]...)
+	}
+}
+
+// The order in which columns are dropped from a listing that is too wide for the
+// terminal. The size and the name are always kept.
+var fitDropOrder = []string{"User Group", "Date Modified", "Files", "Type", "Octal", "Permissions"}
+
+// Drops the least important columns until the rows fit within the given width. Rows
+// that are still too wide with only the size and name left are printed as they are.
+func fitColumns(columns []*column, spacer string, limit int) []*column {
+	for _, header := range fitDropOrder {
+		if tableWidth(columns, spacer) <= limit {
+			break
+		}
+
+		var kept []*column
+
+		for _, col := range columns {
+			if col.header != header {
+				kept = append(kept, col)
+			}
+		}
+
+		columns = kept
+	}
+
+	return columns
+}
+
+// Returns the width of the widest row the columns print.
+func tableWidth(columns []*column, spacer string) int {
+	total := 0
+
+	for i, col := range columns {
+		if i > 0 {
+			total += len(spacer)
+		}
+
+		total += col.widest()
+	}
+
+	return total
+}
+
+// Removes the columns that hold the same value for every file, as they tell nothing
+// about the individual entries. The last column, the name, is always kept.
+func dropUniformColumns(columns []*column) []*column {
+	var kept []*column
+
+	for i, col := range columns {
+		if i == len(columns)-1 || !isUniform(col) {
+			kept = append(kept, col)
+		}
+	}
+
+	return kept
+}
+
+func isUniform(col *column) bool {
+	for _, c := range col.cells {
+		if c.String() != col.cells[0].String() {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,164 @@
+package list
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDisplayWidth(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"main.go", 7},
+		{"日本語", 6},
+		{"ｶﾀｶﾅ", 4},
+		{"mixed日本", 9},
+		{"äöü", 3},
+	}
+
+	for _, test := range tests {
+		if got := displayWidth(test.text); got != test.want {
+			t.Errorf("displayWidth(%q) = %d, want %d", test.text, got, test.want)
+		}
+	}
+}
+
+func TestGridWideCharacters(t *testing.T) {
+	names := []string{"日本語.txt", "a", "中文", "bb", "c", "dd"}
+	cells := make([]cell, len(names))
+
+	for i, name := range names {
+		cells[i] = cell{{name, nil}}
+	}
+
+	var out bytes.Buffer
+	printGrid(&out, cells, 20, "  ", false)
+	rows := lines(out.String())
+	want := []string{"日本語.txt  中文  c", "a           bb    dd"}
+
+	if strings.Join(rows, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q, want %q", rows, want)
+	}
+}
+
+func TestGridPadNames(t *testing.T) {
+	var cells []cell
+
+	for _, name := range []string{"a", "b", "much-longer", "c"} {
+		cells = append(cells, cell{{name, nil}, {"/", nil}})
+	}
+
+	tests := []struct {
+		padNames bool
+		want     []string
+	}{
+		{false, []string{"a/  much-longer/", "b/  c/"}},
+		{true, []string{"a/  much-longer/", "b/  c          /"}},
+	}
+
+	for _, test := range tests {
+		var out bytes.Buffer
+		printGrid(&out, cells, 20, "  ", test.padNames)
+
+		if rows := lines(out.String()); strings.Join(rows, "\n") != strings.Join(test.want, "\n") {
+			t.Errorf("padNames %v: got %q, want %q", test.padNames, rows, test.want)
+		}
+	}
+}
+
+func TestBorder(t *testing.T) {
+	dir := fixture(t, map[string]string{"a": "1", "bb": "22"})
+
+	tests := []struct {
+		header bool
+		rows   int
+	}{
+		{false, 4},
+		{true, 6},
+	}
+
+	for _, test := range tests {
+		out := lines(render(t, dir, func(opts *Options) { opts.Border, opts.Header = true, test.header }))
+
+		if len(out) != test.rows {
+			t.Fatalf("header %v: got %d lines, want %d:\n%s", test.header, len(out), test.rows, strings.Join(out, "\n"))
+		}
+
+		top, bottom := out[0], out[len(out)-1]
+
+		if !strings.HasPrefix(top, "┌─") || !strings.HasSuffix(top, "─┐") || !strings.Contains(top, "┬") {
+			t.Errorf("header %v: top %q", test.header, top)
+		}
+
+		if !strings.HasPrefix(bottom, "└─") || !strings.HasSuffix(bottom, "─┘") || !strings.Contains(bottom, "┴") {
+			t.Errorf("header %v: bottom %q", test.header, bottom)
+		}
+
+		for _, row := range out[1 : len(out)-1] {
+			if !strings.HasPrefix(row, "├") && (!strings.HasPrefix(row, "│ ") || strings.Count(row, "│") != strings.Count(top, "┬")+2) {
+				t.Errorf("header %v: row %q", test.header, row)
+			}
+
+			if displayWidth(row) != displayWidth(top) {
+				t.Errorf("header %v: row %q not as wide as the frame", test.header, row)
+			}
+		}
+
+		if test.header && (!strings.HasPrefix(out[2], "├─") || !strings.Contains(out[1], "│ Name │")) {
+			t.Errorf("header not framed: %q", out[1:3])
+		}
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	dir := fixture(t, map[string]string{"a.go": "1", "b|c.md": "22"})
+
+	out := lines(render(t, dir, func(opts *Options) { opts.Markdown, opts.Color = true, "always" }))
+
+	if len(out) != 4 {
+		t.Fatalf("got %d lines, want a header, a divider and a row per entry:\n%s", len(out), strings.Join(out, "\n"))
+	}
+
+	// The owner column is as wide as the names of the user running the tests
+	username, groupname, _ := fileOwner(lstat(t, filepath.Join(dir, "a.go")))
+	owner := len(username + " " + groupname)
+
+	if owner < len("User Group") {
+		owner = len("User Group")
+	}
+
+	if want := "| Permissions | Size | " + fmt.Sprintf("%-*s", owner, "User Group") + " | Date Modified | Name    |"; out[0] != want {
+		t.Errorf("header %q, want %q", out[0], want)
+	}
+
+	if want := "| ----------- | ---: | " + strings.Repeat("-", owner) + " | ------------: | ------- |"; out[1] != want {
+		t.Errorf("divider %q, want %q", out[1], want)
+	}
+
+	for _, row := range out[2:] {
+		if strings.Contains(row, "\x1b[") || len(row) != len(out[0]) {
+			t.Errorf("row %q coloured or not lined up", row)
+		}
+	}
+
+	if !strings.HasSuffix(out[3], `| b\|c.md |`) {
+		t.Errorf("pipe in the name not escaped: %q", out[3])
+	}
+}
+
+func TestDropUniformColumns(t *testing.T) {
+	uniform := &column{cells: []cell{{{"-", nil}}, {{"-", nil}}}}
+	empty := &column{cells: []cell{{{"", nil}}, {{"", nil}}}}
+	varied := &column{cells: []cell{{{"1", nil}}, {{"2", nil}}}}
+	name := &column{header: "Name", cells: []cell{{{"a", nil}}, {{"b", nil}}}}
+
+	got := dropUniformColumns([]*column{uniform, empty, varied, name})
+
+	if len(got) != 2 || got[0] != varied || got[1] != name {
+		t.Errorf("got %v, want the varied column and the name", got)
+	}
+}
@@ -0,0 +1,209 @@
+package list
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/urfave/cli"
+)
+
+// The file in a directory holding the flags to list that directory with, like -a in a
+// repository of dotfiles.
+const dirConfigName = ".gutconfig"
+
+// The flags a .gutconfig may set, the ones that only change which of the entries of the
+// directory are shown and how. Anyone can leave a .gutconfig in a directory, so a flag
+// that runs a command or reads beyond the directory, like --git or --du, would run just
+// by listing it.
+var dirConfigFlags = map[string]bool{
+	"all": true, "regexp": true, "exclude": true, "ignore-case": true, "only-regular": true,
+	"files-only": true, "no-empty": true, "empty-only": true, "ext": true, "ext-case-sensitive": true,
+	"name-longer-than": true, "name-shorter-than": true, "today": true, "since-boot": true,
+	"sort": true, "reverse": true, "group-directories-first": true, "group-by-extension": true,
+	"group-hardlinks": true, "group-by-day": true, "long": true, "human": true, "si": true,
+	"smart-units": true, "long-units": true, "size-align": true, "dir-mtime-in-size": true,
+	"blank-symlink-size": true, "nanoseconds": true, "color": true, "time-style": true,
+	"relative-within": true, "type-column": true, "octal": true, "dim-hidden": true,
+	"ascii-arrow": true, "collapse-owner-group": true, "classify": true, "quote-rc": true,
+	"fast": true, "owner-sep": true, "pad-names": true, "header": true, "banner": true,
+	"border": true, "grid-threshold": true, "spacing": true, "fit-width": true,
+	"auto-columns": true, "total": true, "files-total": true, "symlinks-as-files": true,
+}
+
+// Returns the long name of the flag going by the name, which may be its short one.
+func longName(name string) string {
+	for _, f := range Flags {
+		names := strings.Split(f.GetName(), ",")
+
+		for _, alias := range names {
+			if strings.TrimSpace(alias) == name {
+				return strings.TrimSpace(names[0])
+			}
+		}
+	}
+
+	return name
+}
+
+// Sets a flag given by its short name under its long name too and the other way around,
+// as only the name used is set by parsing. Where both are given the last one set wins.
+func copyShortNames(set *flag.FlagSet) {
+	given := map[string]string{}
+
+	set.Visit(func(f *flag.Flag) {
+		given[f.Name] = f.Value.String()
+	})
+
+	for _, f := range Flags {
+		names := strings.Split(f.GetName(), ",")
+
+		for _, name := range names {
+			value, ok := given[strings.TrimSpace(name)]
+
+			if !ok {
+				continue
+			}
+
+			for _, other := range names {
+				set.Set(strings.TrimSpace(other), value)
+			}
+		}
+	}
+}
+
+// Returns the options and filters to list the directory with. The flags in its
+// .gutconfig go over the options, like the ones from $GUT_DEFAULT_ARGS, but not over the
+// flags given on the command line, so they do not override what was asked for
+// explicitly. They only apply to the directory itself, not to the directories below it.
+func (l *Lister) dirOptions(path string, opts Options, filters []filter) (Options, []filter) {
+	configPath := filepath.Join(path, dirConfigName)
+	config, err := ioutil.ReadFile(configPath)
+
+	if os.IsNotExist(err) {
+		return opts, filters
+	} else if err != nil {
+		l.warn(err)
+		return opts, filters
+	}
+
+	var lines []string
+
+	for _, line := range strings.Split(string(config), "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), "#") {
+			lines = append(lines, line)
+		}
+	}
+
+	configArgs, err := SplitArgs(strings.Join(lines, "\n"))
+
+	if err != nil {
+		l.warn(fmt.Errorf("%s: %v", configPath, err))
+		return opts, filters
+	}
+
+	set := flag.NewFlagSet(dirConfigName, flag.ContinueOnError)
+	set.SetOutput(ioutil.Discard)
+
+	for _, f := range Flags {
+		f.Apply(set)
+	}
+
+	if err := set.Parse(configArgs); err != nil {
+		l.warn(fmt.Errorf("%s: %v", configPath, err))
+		return opts, filters
+	}
+
+	var forbidden string
+
+	set.Visit(func(f *flag.Flag) {
+		if name := longName(f.Name); forbidden == "" && !dirConfigFlags[name] {
+			forbidden = name
+		}
+	})
+
+	if forbidden != "" {
+		l.warn(fmt.Errorf("%s: --%s can not be set in a %s", configPath, forbidden, dirConfigName))
+		return opts, filters
+	}
+
+	copyShortNames(set)
+
+	given := map[string]bool{}
+
+	for _, name := range opts.commandLine {
+		given[name] = true
+	}
+
+	configured := map[string]bool{}
+
+	set.Visit(func(f *flag.Flag) {
+		configured[f.Name] = !given[f.Name]
+	})
+
+	dirOpts := opts
+	setOptions(&dirOpts, cli.NewContext(nil, set, nil), func(name string) bool { return configured[name] })
+
+	if err := CheckOptions(dirOpts); err != nil {
+		l.warn(fmt.Errorf("%s: %v", configPath, err))
+		return opts, filters
+	}
+
+	dirFilters, err := buildFilters(dirOpts)
+
+	if err != nil {
+		l.warn(fmt.Errorf("%s: %v", configPath, err))
+		return opts, filters
+	}
+
+	return dirOpts, dirFilters
+}
+
+// SplitArgs splits a string into arguments on whitespace like a shell does. Single quotes keep
+// everything up to the closing quote as is, within double quotes and outside of quotes
+// a backslash escapes the next character.
+func SplitArgs(str string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	var quote rune
+	inArg, escaped := false, false
+
+	for _, r := range str {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inArg = true, true
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			current.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if quote != 0 || escaped {
+		return nil, errors.New("unterminated quote or escape")
+	}
+
+	if inArg {
+		args = append(args, current.String())
+	}
+
+	return args, nil
+}
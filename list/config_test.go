@@ -0,0 +1,119 @@
+package list
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDirConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      string
+		commandLine []string
+		set         func(opts *Options)
+		want        string
+		warning     string
+	}{
+		{"applied", "-a", nil, func(opts *Options) {}, ". .. sub .gutconfig .hidden file", ""},
+		{"long name", "# dotfiles\n--all --reverse", nil, func(opts *Options) {}, ". .. file .hidden .gutconfig sub", ""},
+		{"not over the command line", "--sort none --reverse", []string{"reverse"}, func(opts *Options) { opts.Sort = "name" }, "file sub", ""},
+		{"short name on the command line", "-r", []string{"reverse"}, func(opts *Options) {}, "sub file", ""},
+		{"unknown flag", "--no-such-flag", nil, func(opts *Options) {}, "sub file", "flag provided but not defined"},
+		{"invalid options", "--sort sideways", nil, func(opts *Options) {}, "sub file", "unknown sort order"},
+		{"unterminated quote", "--exclude 'sub", nil, func(opts *Options) {}, "sub file", ".gutconfig: unterminated quote or escape"},
+		{"running git", "-a --git", nil, func(opts *Options) {}, "sub file", "--git can not be set in a .gutconfig"},
+		{"walking the tree", "-R", nil, func(opts *Options) {}, "sub file", "--recursive can not be set in a .gutconfig"},
+		{"sizing the directories", "--long --du", nil, func(opts *Options) {}, "sub file", "--du can not be set in a .gutconfig"},
+	}
+
+	for _, test := range tests {
+		dir := fixture(t, map[string]string{".gutconfig": test.config, ".hidden": "", "file": "", "sub/": "", "sub/.inner": "", "sub/inner": ""})
+
+		var warnings []string
+		var out bytes.Buffer
+		lister := &Lister{Output: &out, Warn: func(err error) { warnings = append(warnings, err.Error()) }}
+		opts := DefaultOptions()
+		opts.commandLine = test.commandLine
+		test.set(&opts)
+
+		if err := lister.Render(dir, opts); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := strings.Join(strings.Fields(out.String()), " "); got != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+
+		if test.warning == "" && len(warnings) > 0 {
+			t.Errorf("%s: got warnings %q", test.name, warnings)
+		} else if test.warning != "" && (len(warnings) != 1 || !strings.Contains(warnings[0], test.warning)) {
+			t.Errorf("%s: got warnings %q, want one about %q", test.name, warnings, test.warning)
+		}
+
+		// Only the directory holding the .gutconfig is listed with it
+		if got := strings.Fields(render(t, dir+"/sub", nil)); strings.Join(got, " ") != "inner" {
+			t.Errorf("%s: got %q in the subdirectory", test.name, got)
+		}
+	}
+}
+
+func TestDirConfigDoesNotRunGit(t *testing.T) {
+	dir := fixture(t, map[string]string{".gutconfig": "--long --git", "file": ""})
+	gitIn(t, dir, "init", "-q")
+	gitIn(t, dir, "config", "core.fsmonitor", "touch ran-by-git")
+
+	var warnings []error
+	lister := &Lister{Output: &bytes.Buffer{}, Warn: func(err error) { warnings = append(warnings, err) }}
+
+	if err := lister.Render(dir, DefaultOptions()); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Lstat(filepath.Join(dir, "ran-by-git")); err == nil || len(warnings) != 1 {
+		t.Errorf("git ran for the .gutconfig, warnings %v", warnings)
+	}
+}
+
+func TestDirConfigInRecursiveListing(t *testing.T) {
+	dir := fixture(t, map[string]string{"file": "", ".hidden": "", "sub/.gutconfig": "-a", "sub/.inner": "", "sub/deeper/.deepest": ""})
+
+	out := render(t, dir, func(opts *Options) { opts.Recursive = true })
+	want := ".: sub file sub: deeper .gutconfig .inner sub/deeper:"
+
+	if got := strings.Join(strings.Fields(out), " "); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		str  string
+		want []string
+		err  bool
+	}{
+		{"", nil, false},
+		{"  -a\t--long \n", []string{"-a", "--long"}, false},
+		{`--exclude '\.go$' -s "time"`, []string{"--exclude", `\.go$`, "-s", "time"}, false},
+		{`--regexp "a \"b\" c"`, []string{"--regexp", `a "b" c`}, false},
+		{`a\ b 'it'\''s'`, []string{"a b", "it's"}, false},
+		{`''`, []string{""}, false},
+		{`'open`, nil, true},
+		{`trailing\`, nil, true},
+	}
+
+	for _, test := range tests {
+		got, err := SplitArgs(test.str)
+
+		if (err != nil) != test.err || strings.Join(got, "|") != strings.Join(test.want, "|") || len(got) != len(test.want) {
+			t.Errorf("SplitArgs(%q) = %q, %v, want %q", test.str, got, err, test.want)
+		}
+	}
+
+	// The callers say where the arguments came from
+	if _, err := SplitArgs("'open"); err == nil || err.Error() != "unterminated quote or escape" {
+		t.Errorf("got %v, want the error without its source", err)
+	}
+}
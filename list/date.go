@@ -0,0 +1,146 @@
+package list
+
+import (
+	"strconv"
+	"time"
+)
+
+// The longest a formatted date may get before it is cut off, not counting the seconds
+// added by --nanoseconds.
+const maxDateWidth = 29
+
+const timeLayout = "2 Jan 15:04"
+
+// Files not modified within the last six months show the year instead of the time, like
+// ls does, as the day alone is ambiguous for them.
+const yearLayout = "2 Jan  2006"
+const recentWithin = 4383 * time.Hour
+
+const isoLayout = "2006-01-02 15:04"
+
+// With --nanoseconds the time is shown down to the nanosecond, to tell apart files
+// written within the same second.
+const nanosecondsLayout = "2 Jan 15:04:05.000000000"
+const yearNanosecondsLayout = "2 Jan 2006 15:04:05.000000000"
+const isoNanosecondsLayout = "2006-01-02 15:04:05.000000000"
+
+// The styles --time-style accepts.
+var timeStyles = []string{"default", "both", "iso", "full", "relative"}
+
+func isTimeStyle(style string) bool {
+	for _, known := range timeStyles {
+		if style == known {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Returns the layout of the modification time in the style given by --time-style.
+func dateLayout(t time.Time, opts Options) string {
+	switch {
+	case opts.TimeStyle == "iso" && opts.Nanoseconds:
+		return isoNanosecondsLayout
+	case opts.TimeStyle == "iso":
+		return isoLayout
+	case opts.TimeStyle == "full" && opts.Nanoseconds:
+		return time.RFC3339Nano
+	case opts.TimeStyle == "full":
+		return time.RFC3339
+	case opts.Nanoseconds && isRecent(t):
+		return nanosecondsLayout
+	case opts.Nanoseconds:
+		return yearNanosecondsLayout
+	case !isRecent(t):
+		return yearLayout
+	}
+
+	return timeLayout
+}
+
+// Returns whether the time is within the last six months, so the date is clear without
+// the year.
+func isRecent(t time.Time) bool {
+	return time.Since(t) <= recentWithin && !t.After(time.Now())
+}
+
+// Returns the modification time formatted in the layout, or how long ago it was for the
+// relative styles.
+func dateCell(t time.Time, layout string, opts Options) cell {
+	formattedTime := t.Format(layout)
+
+	if opts.TimeStyle == "relative" {
+		formattedTime = relativeTime(t)
+	} else if opts.RelativeWithin > 0 && time.Since(t) < opts.RelativeWithin {
+		// Recent changes read better as an age, older ones keep the full date
+		formattedTime = relativeTime(t)
+	} else if opts.TimeStyle == "both" {
+		width := maxDateWidth + len(layout) - len(timeLayout)
+		formattedTime = truncate(relativeTime(t)+" ("+formattedTime+")", width)
+	}
+
+	return cell{{formattedTime, colorModTime}}
+}
+
+// An ageUnit is a unit to tell how long ago a time was in, with its name for the date
+// column and the short form for the size column.
+type ageUnit struct {
+	length time.Duration
+	name   string
+	short  string
+}
+
+// The units of an age from the largest to the smallest.
+var ageUnits = []ageUnit{
+	{365 * 24 * time.Hour, "year", "y"},
+	{30 * 24 * time.Hour, "month", "mo"},
+	{24 * time.Hour, "day", "d"},
+	{time.Hour, "hour", "h"},
+	{time.Minute, "minute", "m"},
+	{time.Second, "second", "s"},
+}
+
+// Returns how long ago the time was in the largest unit it is at least one of, in
+// seconds when it is less than a second or in the future.
+func age(t time.Time) (int, ageUnit) {
+	since := time.Since(t)
+
+	for _, unit := range ageUnits {
+		if since >= unit.length {
+			return int(since / unit.length), unit
+		}
+	}
+
+	return 0, ageUnits[len(ageUnits)-1]
+}
+
+// Returns how long ago the given time was, like "3 days ago" or "1 hour ago".
+func relativeTime(t time.Time) string {
+	count, unit := age(t)
+
+	if count != 1 {
+		return strconv.Itoa(count) + " " + unit.name + "s ago"
+	}
+
+	return "1 " + unit.name + " ago"
+}
+
+// Returns how long ago the given time was in a short form, like "2d".
+func shortAge(t time.Time) string {
+	count, unit := age(t)
+
+	return strconv.Itoa(count) + unit.short
+}
+
+// Cuts off a string that is longer than the given number of characters, ending it with
+// an ellipsis.
+func truncate(str string, size int) string {
+	runes := []rune(str)
+
+	if len(runes) <= size {
+		return str
+	}
+
+	return string(runes[:size-1]) + "…"
+}
@@ -0,0 +1,95 @@
+package list
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDateCell(t *testing.T) {
+	old := time.Date(2020, time.March, 1, 12, 30, 45, 123456789, time.Local)
+	recent := time.Now().Add(-3*time.Hour - 2*time.Second).Truncate(time.Second).Add(987654321)
+
+	tests := []struct {
+		name string
+		t    time.Time
+		set  func(opts *Options)
+		want string
+	}{
+		{"default old", old, func(opts *Options) {}, "1 Mar  2020"},
+		{"default recent", recent, func(opts *Options) {}, recent.Format("2 Jan 15:04")},
+		{"iso", old, func(opts *Options) { opts.TimeStyle = "iso" }, "2020-03-01 12:30"},
+		{"full", old, func(opts *Options) { opts.TimeStyle = "full" }, old.Format(time.RFC3339)},
+		{"relative", recent, func(opts *Options) { opts.TimeStyle = "relative" }, "3 hours ago"},
+		{"both", recent, func(opts *Options) { opts.TimeStyle = "both" }, "3 hours ago (" + recent.Format("2 Jan 15:04") + ")"},
+		{"nanoseconds old", old, func(opts *Options) { opts.Nanoseconds = true }, "1 Mar 2020 12:30:45.123456789"},
+		{"nanoseconds recent", recent, func(opts *Options) { opts.Nanoseconds = true }, recent.Format("2 Jan 15:04:05") + ".987654321"},
+		{"nanoseconds iso", old, func(opts *Options) { opts.TimeStyle, opts.Nanoseconds = "iso", true }, "2020-03-01 12:30:45.123456789"},
+		{"nanoseconds full", old, func(opts *Options) { opts.TimeStyle, opts.Nanoseconds = "full", true }, old.Format(time.RFC3339Nano)},
+		{"within relative threshold", recent, func(opts *Options) { opts.RelativeWithin = 24 * time.Hour }, "3 hours ago"},
+		{"beyond relative threshold", old, func(opts *Options) { opts.RelativeWithin = 24 * time.Hour }, "1 Mar  2020"},
+	}
+
+	for _, test := range tests {
+		opts := DefaultOptions()
+		test.set(&opts)
+
+		if got := dateCell(test.t, dateLayout(test.t, opts), opts).String(); got != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestNanosecondsTellFilesApart(t *testing.T) {
+	dir := fixture(t, map[string]string{"first": "", "second": ""})
+	touch(t, filepath.Join(dir, "second"), fixtureTime.Add(1500))
+
+	out := render(t, dir, func(opts *Options) { opts.Long, opts.Nanoseconds = true, true })
+	first, second := fieldsOf(t, out, "first"), fieldsOf(t, out, "second")
+
+	if first[len(first)-2] == second[len(second)-2] {
+		t.Errorf("same time for both files:\n%s", out)
+	}
+}
+
+func TestRelativeTime(t *testing.T) {
+	tests := []struct {
+		age  time.Duration
+		want string
+	}{
+		{-time.Hour, "0 seconds ago"},
+		{30 * time.Second, "30 seconds ago"},
+		{5 * time.Minute, "5 minutes ago"},
+		{2 * time.Hour, "2 hours ago"},
+		{3 * 24 * time.Hour, "3 days ago"},
+		{90 * 24 * time.Hour, "3 months ago"},
+		{800 * 24 * time.Hour, "2 years ago"},
+		{time.Second, "1 second ago"},
+		{time.Minute, "1 minute ago"},
+		{90 * time.Minute, "1 hour ago"},
+		{24 * time.Hour, "1 day ago"},
+		{45 * 24 * time.Hour, "1 month ago"},
+		{400 * 24 * time.Hour, "1 year ago"},
+	}
+
+	for _, test := range tests {
+		if got := relativeTime(time.Now().Add(-test.age)); got != test.want {
+			t.Errorf("relativeTime of %v ago = %q, want %q", test.age, got, test.want)
+		}
+	}
+}
+
+func TestTimeStyleBothFits(t *testing.T) {
+	opts := DefaultOptions()
+	opts.TimeStyle = "both"
+
+	for _, age := range []time.Duration{59 * time.Second, 59 * time.Minute, 23 * time.Hour, 200 * 365 * 24 * time.Hour} {
+		modTime := time.Now().Add(-age)
+		got := dateCell(modTime, dateLayout(modTime, opts), opts).String()
+
+		if width := len([]rune(got)); width > maxDateWidth || !strings.HasSuffix(got, ")") {
+			t.Errorf("got %q of %d characters, want all of it in at most %d", got, width, maxDateWidth)
+		}
+	}
+}
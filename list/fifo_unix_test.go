@@ -0,0 +1,17 @@
+//go:build !windows
+
+package list
+
+import (
+	"syscall"
+	"testing"
+)
+
+// Creates a named pipe at the path.
+func mkfifo(t testing.TB, path string) {
+	t.Helper()
+
+	if err := syscall.Mkfifo(path, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,8 @@
+package list
+
+import "testing"
+
+// Skips the test, as there are no named pipes in the file system on Windows.
+func mkfifo(t testing.TB, path string) {
+	t.Skip("no named pipes on Windows")
+}
@@ -0,0 +1,182 @@
+package list
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// A filter reports whether a file should be listed.
+type filter func(file os.FileInfo) bool
+
+// Returns the filters selected by the options, in the order they are applied.
+func buildFilters(opts Options) ([]filter, error) {
+	var filters []filter
+
+	if len(opts.Regexp) > 0 {
+		match, err := compilePattern("--regexp", opts.Regexp, opts.IgnoreCase)
+
+		if err != nil {
+			return nil, err
+		}
+
+		filters = append(filters, func(file os.FileInfo) bool {
+			return match.MatchString(file.Name())
+		})
+	}
+
+	// Excludes come after the include, to take away from what it matched
+	if len(opts.Exclude) > 0 {
+		match, err := compilePattern("--exclude", opts.Exclude, opts.IgnoreCase)
+
+		if err != nil {
+			return nil, err
+		}
+
+		filters = append(filters, func(file os.FileInfo) bool {
+			return !match.MatchString(file.Name())
+		})
+	}
+
+	if opts.OnlyRegular {
+		filters = append(filters, func(file os.FileInfo) bool {
+			return file.Mode().IsRegular()
+		})
+	}
+
+	if opts.FilesOnly {
+		filters = append(filters, func(file os.FileInfo) bool {
+			return !file.IsDir()
+		})
+	}
+
+	if opts.NoEmpty {
+		filters = append(filters, func(file os.FileInfo) bool {
+			return !isEmptyFile(file)
+		})
+	}
+
+	if opts.EmptyOnly {
+		filters = append(filters, func(file os.FileInfo) bool {
+			return file.IsDir() || isEmptyFile(file)
+		})
+	}
+
+	if len(opts.Extensions) > 0 {
+		filters = append(filters, hasExtension(opts.Extensions, opts.ExtCaseSensitive))
+	}
+
+	if opts.NameLongerThan > 0 {
+		filters = append(filters, func(file os.FileInfo) bool {
+			return utf8.RuneCountInString(file.Name()) > opts.NameLongerThan
+		})
+	}
+
+	if opts.NameShorterThan > 0 {
+		filters = append(filters, func(file os.FileInfo) bool {
+			return utf8.RuneCountInString(file.Name()) < opts.NameShorterThan
+		})
+	}
+
+	if opts.Today {
+		now := time.Now()
+		midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+
+		filters = append(filters, modifiedSince(midnight))
+	}
+
+	if opts.SinceBoot {
+		boot, err := bootTime()
+
+		if err != nil {
+			return nil, err
+		}
+
+		filters = append(filters, modifiedSince(boot))
+	}
+
+	return filters, nil
+}
+
+// Returns whether the file is a regular file without any content. Directories,
+// symlinks and devices report a size of their own and are never empty.
+func isEmptyFile(file os.FileInfo) bool {
+	return file.Mode().IsRegular() && file.Size() == 0
+}
+
+// Compiles the pattern of a flag, case insensitive if asked for. The error names the
+// flag, as the one of the regexp package only shows the pattern.
+func compilePattern(flag string, pattern string, ignoreCase bool) (*regexp.Regexp, error) {
+	if ignoreCase {
+		pattern = "(?i)" + pattern
+	}
+
+	match, err := regexp.Compile(pattern)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s pattern: %v", flag, err)
+	}
+
+	return match, nil
+}
+
+// Returns a filter keeping the files with one of the comma separated extensions, which
+// may be given with or without a leading dot. Unless asked to be case sensitive, jpg
+// also matches photo.JPG. Directories are always kept.
+func hasExtension(list string, caseSensitive bool) filter {
+	normalize := strings.ToLower
+
+	if caseSensitive {
+		normalize = func(extension string) string { return extension }
+	}
+
+	extensions := map[string]bool{}
+
+	for _, extension := range strings.Split(list, ",") {
+		extension = normalize(strings.TrimPrefix(strings.TrimSpace(extension), "."))
+
+		if extension != "" {
+			extensions[extension] = true
+		}
+	}
+
+	return func(file os.FileInfo) bool {
+		extension := strings.TrimPrefix(filepath.Ext(file.Name()), ".")
+
+		return file.IsDir() || extensions[normalize(extension)]
+	}
+}
+
+// Returns a filter keeping the files modified at or after the given time.
+func modifiedSince(t time.Time) filter {
+	return func(file os.FileInfo) bool {
+		return !file.ModTime().Before(t)
+	}
+}
+
+// Returns whether the file passes every filter.
+func keepFile(file os.FileInfo, filters []filter) bool {
+	for _, keep := range filters {
+		if !keep(file) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func filterFiles(files []os.FileInfo, filters []filter) []os.FileInfo {
+	var filteredFiles []os.FileInfo
+
+	for i := 0; i < len(files); i++ {
+		if keepFile(files[i], filters) {
+			filteredFiles = append(filteredFiles, files[i])
+		}
+	}
+
+	return filteredFiles
+}
@@ -0,0 +1,150 @@
+package list
+
+import (
+	"bytes"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFilters(t *testing.T) {
+	dir := fixture(t, map[string]string{
+		"main.go":   "package main",
+		"README.md": "# gut",
+		"photo.JPG": "jpeg",
+		"empty.txt": "",
+		"sub/":      "",
+		"sub/deep":  "",
+	})
+
+	tests := []struct {
+		name string
+		set  func(opts *Options)
+		want []string
+	}{
+		{"regexp", func(opts *Options) { opts.Regexp = "^[mp]" }, []string{"main.go", "photo.JPG"}},
+		{"exclude", func(opts *Options) { opts.Exclude = `\.` }, []string{"sub"}},
+		{"regexp then exclude", func(opts *Options) { opts.Regexp, opts.Exclude = "o", "JPG$" }, []string{"main.go"}},
+		{"ignore case", func(opts *Options) { opts.Regexp, opts.IgnoreCase = "readme|jpg", true }, []string{"README.md", "photo.JPG"}},
+		{"ignore case in exclude", func(opts *Options) { opts.Exclude, opts.IgnoreCase = "[a-z]", true }, nil},
+		{"files only", func(opts *Options) { opts.FilesOnly = true }, []string{"README.md", "empty.txt", "main.go", "photo.JPG"}},
+		{"no empty", func(opts *Options) { opts.NoEmpty = true }, []string{"sub", "README.md", "main.go", "photo.JPG"}},
+		{"empty only", func(opts *Options) { opts.EmptyOnly = true }, []string{"sub", "empty.txt"}},
+		{"extensions", func(opts *Options) { opts.Extensions = "go,.md" }, []string{"sub", "README.md", "main.go"}},
+		{"extensions and files only", func(opts *Options) { opts.Extensions, opts.FilesOnly = "go,md", true }, []string{"README.md", "main.go"}},
+		{"extension in another case", func(opts *Options) { opts.Extensions = "jpg" }, []string{"sub", "photo.JPG"}},
+		{"extension case sensitive", func(opts *Options) { opts.Extensions, opts.ExtCaseSensitive = "jpg", true }, []string{"sub"}},
+	}
+
+	for _, test := range tests {
+		out := render(t, dir, test.set)
+
+		if got := strings.Fields(out); strings.Join(got, " ") != strings.Join(test.want, " ") {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestInvalidPattern(t *testing.T) {
+	dir := fixture(t, map[string]string{"file": ""})
+
+	tests := []struct {
+		set  func(opts *Options)
+		want string
+	}{
+		{func(opts *Options) { opts.Regexp = "(" }, "invalid --regexp pattern"},
+		{func(opts *Options) { opts.Exclude = "[" }, "invalid --exclude pattern"},
+	}
+
+	for _, test := range tests {
+		opts := DefaultOptions()
+		test.set(&opts)
+
+		if err := Render(&bytes.Buffer{}, dir, opts); err == nil || !strings.HasPrefix(err.Error(), test.want) {
+			t.Errorf("got %v, want %s", err, test.want)
+		}
+	}
+}
+
+func TestOnlyRegular(t *testing.T) {
+	dir := fixture(t, map[string]string{"file": "", "dir/": ""})
+	symlink(t, dir, "file", "link")
+
+	mkfifo(t, filepath.Join(dir, "fifo"))
+
+	socket, err := net.Listen("unix", filepath.Join(dir, "socket"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer socket.Close()
+
+	if out := render(t, dir, func(opts *Options) { opts.OnlyRegular = true }); out != "file\n" {
+		t.Errorf("got %q, want only the regular file", out)
+	}
+}
+
+func TestNameLength(t *testing.T) {
+	dir := fixture(t, map[string]string{"abc": "", "abcd": "", "abcde": "", "äöüß": ""})
+
+	tests := []struct {
+		name string
+		set  func(opts *Options)
+		want []string
+	}{
+		{"longer than", func(opts *Options) { opts.NameLongerThan = 4 }, []string{"abcde"}},
+		{"shorter than", func(opts *Options) { opts.NameShorterThan = 4 }, []string{"abc"}},
+		{"runes not bytes", func(opts *Options) { opts.NameLongerThan, opts.NameShorterThan = 3, 5 }, []string{"abcd", "äöüß"}},
+	}
+
+	for _, test := range tests {
+		if got := strings.Fields(render(t, dir, test.set)); strings.Join(got, " ") != strings.Join(test.want, " ") {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestToday(t *testing.T) {
+	dir := fixture(t, map[string]string{"today": "", "yesterday": ""})
+	now := time.Now()
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	touch(t, filepath.Join(dir, "today"), now)
+	touch(t, filepath.Join(dir, "yesterday"), midnight.Add(-time.Minute))
+
+	if out := render(t, dir, func(opts *Options) { opts.Today = true }); out != "today\n" {
+		t.Errorf("got %q, want only today", out)
+	}
+}
+
+func TestFilterFilesKeepsOrder(t *testing.T) {
+	dir := fixture(t, map[string]string{"c": "1", "b": "", "a": "1"})
+	files, err := readDir(dir)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filters, err := buildFilters(Options{NoEmpty: true})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want []os.FileInfo
+
+	for _, file := range files {
+		if file.Size() > 0 {
+			want = append(want, file)
+		}
+	}
+
+	got := filterFiles(files, filters)
+
+	if len(got) != len(want) || got[0].Name() != want[0].Name() || got[1].Name() != want[1].Name() {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,81 @@
+package list
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// A flatWalker prints the entries of a directory tree as a list of paths, keeping track
+// of how many have been printed so the walk can stop as soon as the limit is reached.
+type flatWalker struct {
+	lister  *Lister
+	root    string
+	filters []filter
+	opts    Options
+	printed int
+}
+
+// Prints every entry below the directory as a single list of full paths, without any
+// other details, like find does. Only the entries passing the filters are printed, but
+// all directories are descended into, at most --depth levels deep. Hidden entries are
+// left out like in any listing, unless --all is given.
+func (l *Lister) outputFlat(path string, filters []filter, opts Options) error {
+	walker := &flatWalker{lister: l, root: path, filters: filters, opts: opts}
+
+	return walker.walk(path, opts.Depth)
+}
+
+// Returns whether the requested number of paths has been printed.
+func (w *flatWalker) done() bool {
+	return w.opts.Limit > 0 && w.printed >= w.opts.Limit
+}
+
+// Returns the path as it is printed, relative to the listed directory with --trim-prefix
+// and quoted if asked for.
+func (w *flatWalker) display(path string) string {
+	if !w.opts.TrimPrefix {
+		return escapeName(path, w.opts)
+	}
+
+	if relative, err := filepath.Rel(w.root, path); err == nil {
+		return escapeName(relative, w.opts)
+	}
+
+	return escapeName(path, w.opts)
+}
+
+func (w *flatWalker) walk(path string, depth int) error {
+	files, err := dirReader(path)
+
+	if err != nil {
+		return err
+	}
+
+	files = removePruned(removeHidden(files, w.opts), w.opts)
+	sortFiles(files, path, w.opts)
+
+	for _, file := range files {
+		if w.done() {
+			return nil
+		}
+
+		fullPath := filepath.Join(path, file.Name())
+
+		if keepFile(file, w.filters) {
+			fmt.Fprintln(w.lister.Output, w.display(fullPath))
+			w.printed++
+			w.lister.listed++
+		}
+
+		// Symlinked directories are not followed as the entries come from Lstat
+		if !descendInto(file, w.opts) || depth == 1 {
+			continue
+		}
+
+		if err := w.walk(fullPath, depth-1); err != nil {
+			w.lister.warn(err)
+		}
+	}
+
+	return nil
+}
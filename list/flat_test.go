@@ -0,0 +1,130 @@
+package list
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestFlat(t *testing.T) {
+	dir := fixture(t, map[string]string{
+		"a/b/deep.go": "",
+		"a/top.go":    "",
+		"readme":      "",
+		".hidden/f":   "",
+		"it's":        "",
+	})
+
+	tests := []struct {
+		name string
+		set  func(opts *Options)
+		want []string
+	}{
+		{"everything", func(opts *Options) {}, []string{"a", "a/b", "a/b/deep.go", "a/top.go", "it's", "readme"}},
+		{"depth", func(opts *Options) { opts.Depth = 1 }, []string{"a", "it's", "readme"}},
+		{"filters", func(opts *Options) { opts.Regexp = `\.go$` }, []string{"a/b/deep.go", "a/top.go"}},
+		{"filters and depth", func(opts *Options) { opts.Regexp, opts.Depth = `\.go$`, 2 }, []string{"a/top.go"}},
+		{"all", func(opts *Options) { opts.All, opts.Regexp = true, "^[.f]" }, []string{".hidden"}},
+		{"recurse into hidden", func(opts *Options) { opts.All, opts.RecurseIntoHidden, opts.Regexp = true, true, "^[.f]" }, []string{".hidden", ".hidden/f"}},
+		{"limit", func(opts *Options) { opts.Limit = 2 }, []string{"a", "a/b"}},
+		{"limit with filters", func(opts *Options) { opts.Limit, opts.Regexp = 1, `\.go$` }, []string{"a/b/deep.go"}},
+	}
+
+	for _, test := range tests {
+		out := render(t, dir, func(opts *Options) {
+			opts.Flat = true
+			test.set(opts)
+		})
+
+		var want []string
+
+		for _, path := range test.want {
+			want = append(want, filepath.Join(dir, path))
+		}
+
+		if got := strings.Fields(out); strings.Join(got, "\n") != strings.Join(want, "\n") {
+			t.Errorf("%s: got %q, want %q", test.name, got, want)
+		}
+	}
+}
+
+func TestTrimPrefix(t *testing.T) {
+	dir := fixture(t, map[string]string{"a/b/deep.go": "", "readme": ""})
+
+	tests := []struct {
+		name string
+		set  func(opts *Options)
+		want string
+	}{
+		{"flat", func(opts *Options) {}, "a a/b a/b/deep.go readme"},
+		{"filters", func(opts *Options) { opts.Regexp = `\.go$` }, "a/b/deep.go"},
+		{"without trim prefix", func(opts *Options) { opts.TrimPrefix, opts.Depth = false, 1 }, filepath.Join(dir, "a") + " " + filepath.Join(dir, "readme")},
+	}
+
+	for _, test := range tests {
+		out := render(t, dir, func(opts *Options) {
+			opts.Flat, opts.TrimPrefix = true, true
+			test.set(opts)
+		})
+
+		if got := strings.Join(strings.Fields(out), " "); got != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestFlatQuoteRc(t *testing.T) {
+	dir := fixture(t, map[string]string{"it's": "", "plain": ""})
+
+	out := render(t, dir, func(opts *Options) { opts.Flat, opts.TrimPrefix, opts.QuoteRc = true, true, true })
+
+	if want := "'it''s'\nplain\n"; out != want {
+		t.Errorf("got %q, want %q", out, want)
+	}
+}
+
+func TestFlatLimitStopsTheWalk(t *testing.T) {
+	entries := map[string]string{}
+
+	for i := 0; i < 50; i++ {
+		entries["dir"+strconv.Itoa(i)+"/sub/file"] = ""
+	}
+
+	dir := fixture(t, entries)
+	reads := 0
+	read := dirReader
+
+	defer func() { dirReader = read }()
+
+	dirReader = func(path string) ([]os.FileInfo, error) {
+		reads++
+		return read(path)
+	}
+	lister := &Lister{Output: &strings.Builder{}}
+	opts := DefaultOptions()
+	opts.Flat, opts.Limit = true, 3
+
+	filters, err := buildFilters(opts)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	walker := &flatWalker{lister: lister, root: dir, filters: filters, opts: opts}
+
+	if err := walker.walk(dir, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if walker.printed != 3 || lister.Listed() != 3 {
+		t.Errorf("printed %d and listed %d paths, want 3", walker.printed, lister.Listed())
+	}
+
+	// The root, dir0 and dir0/sub hold the first 3 paths, the walk may look into one more
+	// directory before it finds it is done but not into the other 50
+	if reads > 4 {
+		t.Errorf("read %d directories, want at most 4", reads)
+	}
+}
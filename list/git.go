@@ -0,0 +1,135 @@
+package list
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Returns the name of the branch checked out in the git repository the path is in, or
+// the short commit hash when the HEAD is detached. HEAD is read directly instead of
+// running git.
+func gitBranch(path string) (string, error) {
+	gitDir, err := findGitDir(path)
+
+	if err != nil {
+		return "", err
+	}
+
+	head, err := ioutil.ReadFile(filepath.Join(gitDir, "HEAD"))
+
+	if err != nil {
+		return "", err
+	}
+
+	ref := strings.TrimSpace(string(head))
+
+	if strings.HasPrefix(ref, "ref: ") {
+		return strings.TrimPrefix(strings.TrimPrefix(ref, "ref: "), "refs/heads/"), nil
+	} else if len(ref) > 7 {
+		return ref[:7], nil
+	}
+
+	return ref, nil
+}
+
+// Returns the git directory of the repository the path is in, looking through the
+// parent directories. Worktrees and submodules have a .git file pointing to it instead.
+func findGitDir(path string) (string, error) {
+	for {
+		candidate := filepath.Join(path, ".git")
+		info, err := os.Stat(candidate)
+
+		if err == nil && info.IsDir() {
+			return candidate, nil
+		} else if err == nil {
+			content, err := ioutil.ReadFile(candidate)
+
+			if err != nil {
+				return "", err
+			}
+
+			gitDir := strings.TrimSpace(strings.TrimPrefix(string(content), "gitdir:"))
+
+			if !filepath.IsAbs(gitDir) {
+				gitDir = filepath.Join(path, gitDir)
+			}
+
+			return gitDir, nil
+		}
+
+		parent := filepath.Dir(path)
+
+		if parent == path {
+			return "", errors.New("not a git repository")
+		}
+
+		path = parent
+	}
+}
+
+// Returns the two letter git status of every entry of the directory that has one, keyed
+// by its name, as given by git status --porcelain for the staged and unstaged changes.
+// A directory gets the changes of the files below it. It fails when the directory is
+// not in a git work tree or git is not installed.
+func gitStatuses(path string) (map[string]string, error) {
+	prefix, err := exec.Command("git", "-C", path, "rev-parse", "--show-prefix").Output()
+
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := exec.Command("git", "-C", path, "status", "--porcelain", "-z", "--ignored", ".").Output()
+
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := map[string]string{}
+	records := bytes.Split(out, []byte{0})
+
+	for i := 0; i < len(records); i++ {
+		record := string(records[i])
+
+		if len(record) < 4 {
+			continue
+		}
+
+		status := record[:2]
+
+		// A rename or copy is followed by the path it was renamed or copied from
+		if status[0] == 'R' || status[0] == 'C' {
+			i++
+		}
+
+		// The paths are relative to the top of the work tree, only the entry of the
+		// listed directory they are in is of interest
+		relative := strings.TrimPrefix(record[3:], strings.TrimSpace(string(prefix)))
+		name := strings.SplitN(relative, "/", 2)[0]
+		statuses[name] = mergeGitStatus(statuses[name], status)
+	}
+
+	return statuses, nil
+}
+
+// Combines the status of two files below the same directory, keeping a change in the
+// index or the work tree from either.
+func mergeGitStatus(current string, status string) string {
+	if current == "" {
+		return status
+	}
+
+	merged := []byte(current)
+
+	for i := range merged {
+		if merged[i] == ' ' {
+			merged[i] = status[i]
+		}
+	}
+
+	return string(merged)
+}
@@ -0,0 +1,180 @@
+package list
+
+import (
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Runs git in the directory, skipping the test when git is not installed.
+func gitIn(t testing.TB, dir string, args ...string) string {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	args = append([]string{"-C", dir, "-c", "user.name=gut", "-c", "user.email=gut@example.com"}, args...)
+	out, err := exec.Command("git", args...).CombinedOutput()
+
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+
+	return strings.TrimSpace(string(out))
+}
+
+func TestGitBranch(t *testing.T) {
+	dir := fixture(t, map[string]string{"file": "", "sub/": ""})
+	gitIn(t, dir, "init", "-q")
+	gitIn(t, dir, "checkout", "-q", "-b", "feature/listing")
+	gitIn(t, dir, "add", ".")
+	gitIn(t, dir, "commit", "-q", "-m", "initial")
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{"branch", dir, "feature/listing"},
+		{"from a subdirectory", filepath.Join(dir, "sub"), "feature/listing"},
+	}
+
+	for _, test := range tests {
+		if got, err := gitBranch(test.path); err != nil || got != test.want {
+			t.Errorf("%s: got %q, %v, want %q", test.name, got, err, test.want)
+		}
+	}
+
+	hash := gitIn(t, dir, "rev-parse", "HEAD")
+	gitIn(t, dir, "checkout", "-q", "--detach")
+
+	if got, err := gitBranch(dir); err != nil || got != hash[:7] {
+		t.Errorf("detached: got %q, %v, want %q", got, err, hash[:7])
+	}
+
+	if _, err := gitBranch(t.TempDir()); err == nil {
+		t.Error("got no error outside of a repository")
+	}
+
+	out := render(t, dir, func(opts *Options) { opts.GitBranch = true })
+
+	if first := lines(out)[0]; first != "on "+hash[:7] {
+		t.Errorf("got banner %q", first)
+	}
+}
+
+func TestGitWorktreeFile(t *testing.T) {
+	dir := fixture(t, map[string]string{"repo/": "", "worktree/": ""})
+	gitDir := filepath.Join(dir, "repo")
+
+	if err := ioutil.WriteFile(filepath.Join(gitDir, "HEAD"), []byte("ref: refs/heads/main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "worktree", ".git"), []byte("gitdir: ../repo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := gitBranch(filepath.Join(dir, "worktree")); err != nil || got != "main" {
+		t.Errorf("got %q, %v, want main", got, err)
+	}
+}
+
+func TestGitStatus(t *testing.T) {
+	dir := fixture(t, map[string]string{"clean": "", "changed": "", "staged": "", "ignored": "", "sub/inner": ""})
+	gitIn(t, dir, "init", "-q")
+
+	if err := ioutil.WriteFile(filepath.Join(dir, ".gitignore"), []byte("ignored\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	gitIn(t, dir, "add", "clean", "changed", "sub", ".gitignore")
+	gitIn(t, dir, "commit", "-q", "-m", "initial")
+
+	for name, content := range map[string]string{"changed": "more", "staged": "new", "untracked": "", "sub/inner": "more"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	gitIn(t, dir, "add", "staged")
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"clean", ""},
+		{"changed", " M"},
+		{"staged", "A "},
+		{"untracked", "??"},
+		{"ignored", "!!"},
+		{"sub", " M"},
+	}
+
+	for _, path := range []string{dir, filepath.Join(dir, "sub")} {
+		statuses, err := gitStatuses(path)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if path != dir {
+			if statuses["inner"] != " M" {
+				t.Errorf("got %q for sub/inner", statuses["inner"])
+			}
+
+			continue
+		}
+
+		for _, test := range tests {
+			if statuses[test.name] != test.want {
+				t.Errorf("%s: got %q, want %q", test.name, statuses[test.name], test.want)
+			}
+		}
+	}
+
+	out := render(t, dir, func(opts *Options) { opts.Long, opts.Git = true, true })
+
+	if line := lineOf(t, out, "untracked"); !strings.Contains(line, " ?? ") {
+		t.Errorf("got %q, want the untracked status", line)
+	}
+}
+
+func TestGitStatusOutsideRepository(t *testing.T) {
+	dir := fixture(t, map[string]string{"file": ""})
+	t.Setenv("GIT_CEILING_DIRECTORIES", filepath.Dir(dir))
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	if _, err := gitStatuses(dir); err == nil {
+		t.Error("got no error outside of a repository")
+	}
+
+	out := render(t, dir, func(opts *Options) { opts.Long, opts.Git = true, true })
+
+	if want := render(t, dir, func(opts *Options) { opts.Long = true }); out != want {
+		t.Errorf("got %q, want no git column outside of a repository", out)
+	}
+}
+
+func TestMergeGitStatus(t *testing.T) {
+	tests := []struct {
+		current, status, want string
+	}{
+		{"", " M", " M"},
+		{" M", "A ", "AM"},
+		{"M ", "M ", "M "},
+		{"??", " M", "??"},
+	}
+
+	for _, test := range tests {
+		if got := mergeGitStatus(test.current, test.status); got != test.want {
+			t.Errorf("mergeGitStatus(%q, %q) = %q, want %q", test.current, test.status, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package list
+
+import (
+	"fmt"
+	"html"
+	"io"
+
+	"github.com/fatih/color"
+)
+
+// The CSS reproducing each of the colours in HTML output.
+var htmlStyles = map[*color.Color]string{
+	colorModTime:       "color: #3465a4",
+	colorPermDir:       "color: #3465a4; font-weight: bold",
+	colorPermOther:     "color: #06989a",
+	colorPermRead:      "color: #c4a000",
+	colorPermWrite:     "color: #cc0000",
+	colorPermExecute:   "color: #4e9a06",
+	colorPermNone:      "color: #c4a000",
+	colorFileSize:      "color: #4e9a06; font-weight: bold",
+	colorOwner:         "color: #c4a000; font-weight: bold",
+	colorSymlinkDest:   "color: #06989a",
+	colorSymlinkSource: "color: #75507b; font-weight: bold",
+	colorHeader:        "text-decoration: underline",
+	colorHidden:        "opacity: 0.5",
+	colorDirName:       "color: #3465a4; font-weight: bold",
+	colorProtected:     "color: #75507b; text-decoration: underline",
+}
+
+// Returns the cell as HTML, every coloured segment in a span styled like the colour.
+func (c cell) html() string {
+	var text string
+
+	for _, s := range c {
+		if style, ok := htmlStyles[s.color]; ok {
+			text += `<span style="` + style + `">` + html.EscapeString(s.text) + "</span>"
+		} else {
+			text += html.EscapeString(s.text)
+		}
+	}
+
+	return text
+}
+
+// Prints the columns as an HTML table with inline styles, so it can be pasted into
+// reports and emails as is.
+func printHTML(w io.Writer, columns []*column) {
+	fmt.Fprintln(w, `<table style="font-family: monospace; border-collapse: collapse">`)
+	fmt.Fprint(w, "<thead><tr>")
+
+	for _, col := range columns {
+		fmt.Fprint(w, `<th style="`+htmlAlignment(col)+`; `+htmlStyles[colorHeader]+`">`+html.EscapeString(col.header)+"</th>")
+	}
+
+	fmt.Fprintln(w, "</tr></thead>")
+	fmt.Fprintln(w, "<tbody>")
+
+	if len(columns) > 0 {
+		for row := range columns[0].cells {
+			fmt.Fprint(w, "<tr>")
+
+			for _, col := range columns {
+				fmt.Fprint(w, `<td style="`+htmlAlignment(col)+`; padding: 0 0.5em">`+col.cells[row].html()+"</td>")
+			}
+
+			fmt.Fprintln(w, "</tr>")
+		}
+	}
+
+	fmt.Fprintln(w, "</tbody>")
+	fmt.Fprintln(w, "</table>")
+}
+
+func htmlAlignment(col *column) string {
+	if col.alignRight {
+		return "text-align: right"
+	}
+
+	return "text-align: left"
+}
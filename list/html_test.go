@@ -0,0 +1,34 @@
+package list
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHTML(t *testing.T) {
+	dir := fixture(t, map[string]string{"a.go": "1", "<b>&.txt": "22", "sub/": ""})
+
+	out := render(t, dir, func(opts *Options) { opts.HTML = true })
+
+	if !strings.HasPrefix(out, "<table") || !strings.HasSuffix(out, "</table>\n") {
+		t.Errorf("no table around the listing:\n%s", out)
+	}
+
+	if rows := strings.Count(out, "<tr>"); rows != 4 {
+		t.Errorf("got %d rows, want a header and one per entry", rows)
+	}
+
+	if !strings.Contains(out, "&lt;b&gt;&amp;.txt") || strings.Contains(out, "<b>&") {
+		t.Errorf("name not escaped:\n%s", out)
+	}
+
+	for _, style := range []string{htmlStyles[colorDirName], htmlStyles[colorFileSize], htmlStyles[colorHeader]} {
+		if !strings.Contains(out, style) {
+			t.Errorf("no %q in:\n%s", style, out)
+		}
+	}
+
+	if strings.Contains(out, "\x1b[") {
+		t.Errorf("terminal colours in the HTML:\n%s", out)
+	}
+}
@@ -0,0 +1,20 @@
+//go:build !windows
+
+package list
+
+import (
+	"os"
+	"syscall"
+)
+
+// Returns the device and inode number of the file, which together tell whether two
+// entries are hard links to the same file. Files in an archive have neither.
+func fileInode(file os.FileInfo) (dev uint64, ino uint64, ok bool) {
+	stat, ok := file.Sys().(*syscall.Stat_t)
+
+	if !ok {
+		return 0, 0, false
+	}
+
+	return uint64(stat.Dev), uint64(stat.Ino), true
+}
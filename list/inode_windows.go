@@ -0,0 +1,10 @@
+//go:build windows
+
+package list
+
+import "os"
+
+// The file details on Windows carry no inode number, so no hard links are found.
+func fileInode(file os.FileInfo) (dev uint64, ino uint64, ok bool) {
+	return 0, 0, false
+}
@@ -0,0 +1,128 @@
+package list
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// A jsonEntry holds the details of a file as printed by --json. The path is the name
+// under the path it was listed by, the owner and group are the same as in the columns and
+// the target is only set for symlinks.
+type jsonEntry struct {
+	Name    string `json:"name"`
+	Path    string `json:"path"`
+	Size    int64  `json:"size"`
+	Mode    string `json:"mode"`
+	ModTime string `json:"modTime"`
+	IsDir   bool   `json:"isDir"`
+	Owner   string `json:"owner"`
+	Group   string `json:"group"`
+	Target  string `json:"target,omitempty"`
+}
+
+// A jsonColumn describes a column of the listing as printed by --describe-columns, its
+// width being that of its widest cell.
+type jsonColumn struct {
+	Name  string `json:"name"`
+	Width int    `json:"width"`
+	Align string `json:"align"`
+}
+
+// Prints the columns the listing would have as a JSON array, leaving out the files.
+func outputColumnsJSON(w io.Writer, columns []*column) error {
+	described := []jsonColumn{}
+
+	for _, col := range columns {
+		align := "left"
+
+		if col.alignRight {
+			align = "right"
+		}
+
+		described = append(described, jsonColumn{col.header, col.width, align})
+	}
+
+	return encodeJSON(w, described)
+}
+
+// Returns the files of the directory as the objects --json prints for them. The path is
+// the directory as given, blank for the matches of a glob and a file given on the command
+// line, which are named by their path already. With --fast the owner and group are the
+// ids, without looking up their names.
+func jsonEntries(files []os.FileInfo, path string, opts Options) []jsonEntry {
+	entries := []jsonEntry{}
+
+	for _, file := range files {
+		entry := jsonEntry{
+			Name:    file.Name(),
+			Path:    filepath.Join(path, file.Name()),
+			Size:    file.Size(),
+			Mode:    octalMode(file.Mode()),
+			ModTime: file.ModTime().Format(time.RFC3339),
+			IsDir:   file.IsDir(),
+		}
+
+		// Names that can not be looked up are left as the ids, like in the columns
+		if opts.Fast {
+			entry.Owner, entry.Group, _ = ownerIDs(file)
+		} else {
+			entry.Owner, entry.Group, _ = fileOwner(file)
+		}
+
+		if file.Mode()&os.ModeSymlink != 0 {
+			entry.Target, _ = os.Readlink(entry.Path)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+// Prints the listings of the paths as JSON, with --json a single array of the entries of
+// all of them and with --summary-json a single summary of them all. The output has the
+// same shape however many paths are given, to not depend on what a glob expands to. With
+// several paths one that can not be read is left out and warned about.
+func (l *Lister) outputJSON(paths []string, filters []filter, opts Options) error {
+	entries := []jsonEntry{}
+	total := summary{ByExtension: map[string]*extensionSummary{}}
+
+	for _, path := range paths {
+		dir, err := l.readListing(path, filters, opts)
+
+		if err != nil && len(paths) == 1 {
+			return err
+		} else if err != nil {
+			l.warn(err)
+			continue
+		}
+
+		l.listed += len(dir.files)
+		total.add(summarize(dir.files, dir.opts.SymlinksAsFiles))
+
+		// A directory is read by its absolute path, its entries are named under the path
+		// as given
+		if dir.path == "" {
+			path = ""
+		}
+
+		entries = append(entries, jsonEntries(dir.files, path, dir.opts)...)
+	}
+
+	if opts.SummaryJSON {
+		return encodeJSON(l.Output, total)
+	}
+
+	return encodeJSON(l.Output, entries)
+}
+
+// Prints the value as indented JSON.
+func encodeJSON(w io.Writer, value interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(value)
+}
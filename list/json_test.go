@@ -0,0 +1,216 @@
+package list
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJSON(t *testing.T) {
+	dir := fixture(t, map[string]string{"file.go": "12345", "sub/": ""})
+	symlink(t, dir, "file.go", "link")
+
+	out := render(t, dir, func(opts *Options) { opts.JSON, opts.Color = true, "always" })
+	var entries []jsonEntry
+
+	if err := json.Unmarshal([]byte(out), &entries); err != nil {
+		t.Fatalf("invalid JSON %q: %v", out, err)
+	}
+
+	username, groupname, _ := fileOwner(lstat(t, filepath.Join(dir, "file.go")))
+	want := []jsonEntry{
+		{"sub", filepath.Join(dir, "sub"), 4096, "0755", fixtureTime.Format(time.RFC3339), true, username, groupname, ""},
+		{"file.go", filepath.Join(dir, "file.go"), 5, "0644", fixtureTime.Format(time.RFC3339), false, username, groupname, ""},
+		{"link", filepath.Join(dir, "link"), 7, "0777", "", false, username, groupname, "file.go"},
+	}
+
+	if len(entries) != len(want) {
+		t.Fatalf("got %v, want %v", entries, want)
+	}
+
+	for i, entry := range entries {
+		if entry.Name == "sub" {
+			// The size of a directory depends on the file system
+			entry.Size = want[i].Size
+		} else if entry.Name == "link" {
+			entry.ModTime = ""
+		}
+
+		if entry != want[i] {
+			t.Errorf("got %+v, want %+v", entry, want[i])
+		}
+	}
+}
+
+func TestJSONFast(t *testing.T) {
+	dir := fixture(t, map[string]string{"file": ""})
+
+	out := render(t, dir, func(opts *Options) { opts.JSON, opts.Fast = true, true })
+	var entries []jsonEntry
+
+	if err := json.Unmarshal([]byte(out), &entries); err != nil {
+		t.Fatalf("invalid JSON %q: %v", out, err)
+	}
+
+	uid, gid, _ := ownerIDs(lstat(t, filepath.Join(dir, "file")))
+
+	if len(entries) != 1 || entries[0].Owner != uid || entries[0].Group != gid {
+		t.Errorf("got %+v, want the owner %q and group %q", entries, uid, gid)
+	}
+}
+
+func TestSummaryJSON(t *testing.T) {
+	dir := fixture(t, map[string]string{"a.go": "12", "b.go": "1234", "c.md": "1", "sub/": "", "sub/deep.go": "123456"})
+	symlink(t, dir, "a.go", "link")
+
+	out := render(t, dir, func(opts *Options) { opts.SummaryJSON = true })
+	var total summary
+
+	if err := json.Unmarshal([]byte(out), &total); err != nil {
+		t.Fatalf("invalid JSON %q: %v", out, err)
+	}
+
+	if total.Files != 3 || total.Directories != 1 || total.Symlinks != 1 || total.Size != 7 {
+		t.Errorf("got %+v, want 3 files, 1 directory, 1 symlink and 7 bytes", total)
+	}
+
+	if goFiles := total.ByExtension[".go"]; goFiles == nil || goFiles.Files != 2 || goFiles.Size != 6 {
+		t.Errorf("got %+v for .go, want 2 files of 6 bytes", goFiles)
+	}
+
+	if strings.Contains(out, `"name"`) {
+		t.Errorf("summary holds the entries: %s", out)
+	}
+}
+
+func TestJSONPaths(t *testing.T) {
+	first := fixture(t, map[string]string{"a": "1"})
+	second := fixture(t, map[string]string{"b": "12", "c": "123"})
+
+	tests := []struct {
+		name     string
+		args     []string
+		paths    []string
+		size     int64
+		problems int
+	}{
+		{"one directory", []string{second}, []string{filepath.Join(second, "b"), filepath.Join(second, "c")}, 5, 0},
+		{"one file", []string{filepath.Join(second, "c")}, []string{filepath.Join(second, "c")}, 3, 0},
+		{"several paths", []string{first, filepath.Join(first, "missing"), second}, []string{filepath.Join(first, "a"), filepath.Join(second, "b"), filepath.Join(second, "c")}, 6, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var out bytes.Buffer
+			var problems []error
+			opts := DefaultOptions()
+			opts.JSON = true
+			lister := &Lister{Output: &out, Warn: func(err error) { problems = append(problems, err) }}
+
+			if err := lister.RenderAll(test.args, opts); err != nil {
+				t.Fatal(err)
+			}
+
+			var entries []jsonEntry
+
+			if err := json.Unmarshal(out.Bytes(), &entries); err != nil {
+				t.Fatalf("invalid JSON %q: %v", out.String(), err)
+			}
+
+			var paths []string
+
+			for _, entry := range entries {
+				paths = append(paths, entry.Path)
+			}
+
+			if strings.Join(paths, " ") != strings.Join(test.paths, " ") {
+				t.Errorf("got %v, want %v", paths, test.paths)
+			}
+
+			if len(problems) != test.problems || lister.Listed() != len(test.paths) {
+				t.Errorf("got problems %v and %d entries, want %d and %d", problems, lister.Listed(), test.problems, len(test.paths))
+			}
+
+			out.Reset()
+			opts.JSON, opts.SummaryJSON = false, true
+
+			if err := (&Lister{Output: &out, Warn: func(error) {}}).RenderAll(test.args, opts); err != nil {
+				t.Fatal(err)
+			}
+
+			var total summary
+
+			if err := json.Unmarshal(out.Bytes(), &total); err != nil {
+				t.Fatalf("invalid JSON %q: %v", out.String(), err)
+			}
+
+			if total.Files != len(test.paths) || total.Size != test.size {
+				t.Errorf("got %+v, want %d files of %d bytes", total, len(test.paths), test.size)
+			}
+		})
+	}
+}
+
+func TestJSONMissingPath(t *testing.T) {
+	dir := fixture(t, map[string]string{})
+	opts := DefaultOptions()
+	opts.JSON = true
+
+	if err := Render(&bytes.Buffer{}, filepath.Join(dir, "missing"), opts); err == nil {
+		t.Error("no error for a single missing path")
+	}
+}
+
+func TestJSONNotRecursive(t *testing.T) {
+	dir := fixture(t, map[string]string{"a": ""})
+
+	for _, set := range []func(opts *Options){
+		func(opts *Options) { opts.JSON, opts.Recursive = true, true },
+		func(opts *Options) { opts.JSON, opts.Tree = true, true },
+		func(opts *Options) { opts.SummaryJSON, opts.Flat = true, true },
+	} {
+		opts := DefaultOptions()
+		set(&opts)
+
+		if err := Render(&bytes.Buffer{}, dir, opts); err == nil {
+			t.Errorf("no error for %+v", opts)
+		}
+	}
+}
+
+func TestDescribeColumns(t *testing.T) {
+	dir := fixture(t, map[string]string{"file": "12345678901", "other-file": ""})
+
+	out := render(t, dir, func(opts *Options) { opts.DescribeColumns, opts.Fast = true, true })
+	var columns []jsonColumn
+
+	if err := json.Unmarshal([]byte(out), &columns); err != nil {
+		t.Fatalf("invalid JSON %q: %v", out, err)
+	}
+
+	want := []jsonColumn{
+		{"Permissions", 10, "left"},
+		{"Size", 5, "right"},
+		{"User Group", 0, "left"},
+		{"Date Modified", 12, "right"},
+		{"Name", 10, "left"},
+	}
+
+	if len(columns) != len(want) {
+		t.Fatalf("got %+v, want %+v", columns, want)
+	}
+
+	for i, col := range columns {
+		if col.Name == "User Group" {
+			// As wide as the ids of the user running the tests
+			want[i].Width = col.Width
+		}
+
+		if col != want[i] {
+			t.Errorf("got %+v, want %+v", col, want[i])
+		}
+	}
+}
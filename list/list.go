@@ -0,0 +1,1383 @@
+package list
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/phayes/permbits"
+)
+
+const defaultSpacer = "  "
+
+// A sizeUnits is a way of showing sizes, the number of bytes or units in the next unit
+// up and the suffixes and full names of the units starting from bytes.
+type sizeUnits struct {
+	step     int64
+	suffixes []string
+	names    []string
+}
+
+var binaryUnits = sizeUnits{1024,
+	[]string{"", "Ki", "Mi", "Gi", "Ti"},
+	[]string{"byte", "kibibyte", "mebibyte", "gibibyte", "tebibyte"},
+}
+
+var siUnits = sizeUnits{1000,
+	[]string{"", "k", "M", "G", "T"},
+	[]string{"byte", "kilobyte", "megabyte", "gigabyte", "terabyte"},
+}
+
+// Colour definitions
+const colorReset = "\x1b[0m"
+
+var colorModTime = color.New(color.FgBlue)
+var colorPermDir = color.New(color.FgBlue, color.Bold)
+var colorPermOther = color.New(color.FgCyan)
+var colorPermRead = color.New(color.FgYellow)
+var colorPermWrite = color.New(color.FgRed)
+var colorPermExecute = color.New(color.FgGreen)
+var colorPermNone = color.New(color.FgYellow)
+var colorFileSize = color.New(color.FgGreen, color.Bold)
+var colorOwner = color.New(color.FgYellow, color.Bold)
+var colorSymlinkDest = color.New(color.FgCyan)
+var colorSymlinkSource = color.New(color.FgMagenta, color.Bold)
+var colorHeader = color.New(color.FgWhite, color.Underline)
+var colorHidden = color.New(color.Faint)
+var colorDirName = color.New(color.FgBlue, color.Bold)
+var colorProtected = color.New(color.FgMagenta, color.Underline)
+
+// A namedEntry is a file shown under another name than its base name, like its path
+// within an archive.
+type namedEntry struct {
+	os.FileInfo
+	name string
+}
+
+func (e namedEntry) Name() string {
+	return e.name
+}
+
+type byDir []os.FileInfo
+
+func (a byDir) Len() int      { return len(a) }
+func (a byDir) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a byDir) Less(i, j int) bool {
+	if a[i].IsDir() && !a[j].IsDir() {
+		return true
+	} else if !a[i].IsDir() && a[j].IsDir() {
+		return false
+	} else {
+		return a[i].Name() < a[j].Name()
+	}
+}
+
+// Pads a string with whitespaces to the left with a specific size and returns a new string.
+func padLeft(size int, str string) string {
+	return strings.Repeat(" ", size) + str
+}
+
+func permissionsCell(file os.FileMode) cell {
+	permissions := permbits.FileMode(file)
+	var perm cell
+
+	if file.IsDir() {
+		perm = append(perm, segment{"d", colorPermDir})
+	} else if file.IsRegular() {
+		perm = append(perm, segment{"-", colorPermNone})
+	} else {
+		// We need to do more to find out this file mode
+		perm = append(perm, segment{strings.ToLower(string(file.String()[0])), colorPermOther})
+	}
+
+	perm = append(perm, permissionBit(permissions.UserRead(), "r", colorPermRead))
+	perm = append(perm, permissionBit(permissions.UserWrite(), "w", colorPermWrite))
+	perm = append(perm, permissionBit(permissions.UserExecute(), "x", colorPermExecute))
+	perm = append(perm, permissionBit(permissions.GroupRead(), "r", colorPermRead))
+	perm = append(perm, permissionBit(permissions.GroupWrite(), "w", colorPermWrite))
+	perm = append(perm, permissionBit(permissions.GroupExecute(), "x", colorPermExecute))
+	perm = append(perm, permissionBit(permissions.OtherRead(), "r", colorPermRead))
+	perm = append(perm, permissionBit(permissions.OtherWrite(), "w", colorPermWrite))
+	perm = append(perm, permissionBit(permissions.OtherExecute(), "x", colorPermExecute))
+
+	return perm
+}
+
+// Returns the kind of file in words.
+func typeCell(file os.FileMode) cell {
+	switch {
+	case file.IsDir():
+		return cell{{"dir", colorPermDir}}
+	case file&os.ModeSymlink != 0:
+		return cell{{"symlink", colorSymlinkDest}}
+	case file&os.ModeNamedPipe != 0:
+		return cell{{"fifo", colorPermOther}}
+	case file&os.ModeSocket != 0:
+		return cell{{"socket", colorPermOther}}
+	case file&os.ModeDevice != 0:
+		return cell{{"device", colorPermOther}}
+	case file.IsRegular():
+		return cell{{"file", nil}}
+	}
+
+	return cell{{"other", colorPermOther}}
+}
+
+// Returns the permissions as an octal number like chmod takes, including the setuid,
+// setgid and sticky bits.
+func octalMode(mode os.FileMode) string {
+	octal := uint32(mode.Perm())
+
+	if mode&os.ModeSetuid != 0 {
+		octal |= 04000
+	}
+
+	if mode&os.ModeSetgid != 0 {
+		octal |= 02000
+	}
+
+	if mode&os.ModeSticky != 0 {
+		octal |= 01000
+	}
+
+	return fmt.Sprintf("%04o", octal)
+}
+
+// Returns the segment for a single permission bit, a dash when it is not set.
+func permissionBit(set bool, char string, c *color.Color) segment {
+	if set {
+		return segment{char, c}
+	}
+
+	return segment{"-", colorPermNone}
+}
+
+// Returns the size in the largest unit it fills at least one of, in binary units or
+// with --si in powers of 1000. Sizes under one unit are shown as the number of bytes.
+// With --long-units the unit is written out, like 1.5 gibibytes.
+func friendlySize(size int64, opts Options) string {
+	units := binaryUnits
+
+	if opts.SI {
+		units = siUnits
+	}
+
+	unit := int64(1)
+	i := 0
+
+	for i < len(units.suffixes)-1 && size >= unit*units.step {
+		unit *= units.step
+		i++
+	}
+
+	value := strconv.FormatInt(size, 10)
+
+	if i > 0 {
+		value = inUnit(size, unit, opts.SmartUnits)
+
+		// Rounding can fill up the unit, 1023.99Ki is shown as 1Mi rather than 1024Ki
+		if rounded, _ := strconv.ParseFloat(value, 64); rounded >= float64(units.step) && i < len(units.suffixes)-1 {
+			unit *= units.step
+			i++
+			value = inUnit(size, unit, opts.SmartUnits)
+		}
+	}
+
+	if !opts.LongUnits {
+		return value + units.suffixes[i]
+	} else if value == "1" {
+		return value + " " + units.names[i]
+	}
+
+	return value + " " + units.names[i] + "s"
+}
+
+// Returns the size as a number of units rounded to one decimal, without the decimal
+// when it comes out as a whole number. With smart units the decimal is only kept below
+// 10 units, where it still tells sizes apart: 1.5Gi but 512Ki.
+func inUnit(size int64, unit int64, smart bool) string {
+	value := float64(size) / float64(unit)
+
+	if smart && value >= 10 {
+		return strconv.FormatFloat(value, 'f', 0, 64)
+	}
+
+	return strings.TrimSuffix(strconv.FormatFloat(value, 'f', 1, 64), ".0")
+}
+
+// Formats a number of bytes, in binary units when human readable sizes are asked for and
+// as the plain number otherwise. Every size shown goes through here so they all switch
+// together.
+func formatSize(size int64, opts Options) string {
+	if opts.Human || opts.SmartUnits || opts.SI || opts.LongUnits {
+		return friendlySize(size, opts)
+	}
+
+	return strconv.FormatInt(size, 10)
+}
+
+// Returns the size of the file. Directories have no size of their own, they show a
+// dash, with --du the size of everything below them or with --dir-mtime-in-size how
+// long ago they were modified. The size of a symlink is only the length of its target,
+// which can be blanked too.
+func sizeCell(file os.FileInfo, path string, opts Options) cell {
+	if file.IsDir() && opts.DU {
+		size, complete := diskUsage(filepath.Join(path, file.Name()))
+
+		if !complete {
+			return cell{{formatSize(size, opts) + "+", colorPermWrite}}
+		}
+
+		return cell{{formatSize(size, opts), colorFileSize}}
+	} else if file.IsDir() && opts.DirMtimeInSize {
+		return cell{{shortAge(file.ModTime()), colorModTime}}
+	} else if file.IsDir() || (opts.BlankSymlinkSize && file.Mode()&os.ModeSymlink != 0) {
+		return cell{{"-", colorPermNone}}
+	}
+
+	return cell{{formatSize(file.Size(), opts), colorFileSize}}
+}
+
+// Returns the number of files below a directory. A count followed by a plus is
+// incomplete because part of the tree could not be read.
+func countCell(file os.FileInfo, path string, opts Options) cell {
+	if !file.IsDir() {
+		return cell{{"-", colorPermNone}}
+	}
+
+	count, complete := countFiles(filepath.Join(path, file.Name()), opts.Depth, opts)
+
+	if complete {
+		return cell{{strconv.Itoa(count), colorFileSize}}
+	} else if count == 0 {
+		return cell{{"?+", colorPermWrite}}
+	}
+
+	return cell{{strconv.Itoa(count) + "+", colorPermWrite}}
+}
+
+// Counts the files in a directory and all of its subdirectories, at most depth levels
+// deep when depth is above zero. Hidden files are counted and hidden directories
+// descended into only with --all, like they are listed. Symlinked directories are never
+// descended into as the files come from Lstat. The returned boolean is false when a
+// directory could not be read.
+func countFiles(path string, depth int, opts Options) (int, bool) {
+	files, err := readDir(path)
+
+	if err != nil {
+		return 0, false
+	}
+
+	count := 0
+	complete := true
+
+	for _, file := range removePruned(removeHidden(files, opts), opts) {
+		if !file.IsDir() {
+			count++
+			continue
+		}
+
+		if depth == 1 {
+			continue
+		}
+
+		subCount, subComplete := countFiles(filepath.Join(path, file.Name()), depth-1, opts)
+		count += subCount
+		complete = complete && subComplete
+	}
+
+	return count, complete
+}
+
+// Returns whether a walk through the directory tree goes into the file. Only
+// directories are, but not symlinks to them as the files come from Lstat, and hidden
+// directories like .git only when asked for.
+func descendInto(file os.FileInfo, opts Options) bool {
+	return file.IsDir() && (opts.RecurseIntoHidden || !strings.HasPrefix(file.Name(), ".")) && !isPruned(file, opts)
+}
+
+// Returns whether the file is a directory left out of recursive listings entirely,
+// because it has not been modified within --prune-older-than.
+func isPruned(file os.FileInfo, opts Options) bool {
+	return opts.PruneOlderThan > 0 && file.IsDir() && time.Since(file.ModTime()) > opts.PruneOlderThan
+}
+
+// Returns the files without the pruned directories.
+func removePruned(files []os.FileInfo, opts Options) []os.FileInfo {
+	var kept []os.FileInfo
+
+	for _, file := range files {
+		if !isPruned(file, opts) {
+			kept = append(kept, file)
+		}
+	}
+
+	return kept
+}
+
+// Returns the files without the dotfiles, unless --all is given.
+func removeHidden(files []os.FileInfo, opts Options) []os.FileInfo {
+	if opts.All {
+		return files
+	}
+
+	var kept []os.FileInfo
+
+	for _, file := range files {
+		if !strings.HasPrefix(file.Name(), ".") {
+			kept = append(kept, file)
+		}
+	}
+
+	return kept
+}
+
+// Returns the entries for the directory itself and its parent that --all lists first,
+// like ls -a does. An entry that can not be read is left out.
+func (l *Lister) dotEntries(path string) []os.FileInfo {
+	var entries []os.FileInfo
+
+	for _, name := range []string{".", ".."} {
+		file, err := os.Lstat(filepath.Join(path, name))
+
+		if err != nil {
+			l.warn(err)
+			continue
+		}
+
+		entries = append(entries, namedEntry{file, name})
+	}
+
+	return entries
+}
+
+// Returns the owner and group of the file. An id without a name, common in containers,
+// is shown as the number and the lookup error is returned along with the cell.
+func ownerCell(file os.FileInfo, opts Options) (cell, error) {
+	// Files in an archive carry no owner we could look up
+	uid, gid, ok := ownerIDs(file)
+
+	if !ok {
+		return cell{{"", colorOwner}}, nil
+	} else if opts.Fast {
+		return cell{{uid, colorOwner}, {opts.OwnerSep + gid, colorOwner}}, nil
+	}
+
+	username, groupname, lookupErr := fileOwner(file)
+
+	if opts.CollapseOwnerGroup && username == groupname {
+		return cell{{username, colorOwner}}, lookupErr
+	}
+
+	// The group is a segment of its own, so the groups can be lined up
+	return cell{{username, colorOwner}, {opts.OwnerSep + groupname, colorOwner}}, lookupErr
+}
+
+// Returns the name of the file, for symlinks followed by where they point to. The
+// target is resolved unless --fast is given, in which case it is shown as stored.
+func nameCell(file os.FileInfo, path string, opts Options) cell {
+	name := escapeName(file.Name(), opts)
+	arrow := symlinkArrow(opts)
+
+	if file.IsDir() {
+		return cell{{name, colorDirName}}
+	}
+
+	if file.Mode()&os.ModeSymlink != 0 && opts.Fast {
+		target, err := os.Readlink(filepath.Join(path, file.Name()))
+
+		if err != nil {
+			return cell{{name, nil}, {arrow + "[unknown]", nil}}
+		}
+
+		return cell{{name, colorSymlinkDest}, {arrow, nil}, {target, colorSymlinkSource}}
+	} else if file.Mode()&os.ModeSymlink != 0 {
+		// Follow the symlink
+		fullFilePath := filepath.Join(path, file.Name())
+		followedPath, err := filepath.EvalSymlinks(fullFilePath)
+
+		if os.IsPermission(err) {
+			// The link exists but we are not allowed to look at where it points to
+			target, _ := os.Readlink(fullFilePath)
+
+			return cell{{name, colorSymlinkDest}, {arrow, nil}, {target, colorSymlinkSource}, {" [no access]", nil}}
+		} else if err != nil && opts.LinkDetail {
+			target, _ := os.Readlink(fullFilePath)
+
+			return cell{{name, colorPermWrite}, {arrow, nil}, {target, nil}, {" (broken)", colorPermWrite}}
+		} else if os.IsNotExist(err) {
+			return cell{{name, colorPermWrite}, {arrow + "[target missing]", nil}}
+		} else if err != nil {
+			// Like a loop of links, or a file used as a directory along the way
+			return cell{{name, colorPermWrite}, {arrow + "[unknown]", nil}}
+		}
+
+		targetColor := colorSymlinkSource
+
+		if target, err := os.Stat(followedPath); err == nil && target.IsDir() {
+			targetColor = colorDirName
+		}
+
+		link := cell{{name, colorSymlinkDest}, {arrow, nil}, {followedPath, targetColor}}
+
+		if opts.LinkDetail {
+			link = append(link, linkTargetSize(followedPath, opts)...)
+		}
+
+		return link
+	}
+
+	return cell{{name, nil}}
+}
+
+// Returns the arrow between a symlink and its target. Plain ASCII is used when asked for
+// or when the locale is set to something other than UTF-8, as not every terminal can
+// show the → then.
+func symlinkArrow(opts Options) string {
+	if opts.ASCIIArrow {
+		return " -> "
+	}
+
+	for _, variable := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if locale := os.Getenv(variable); locale != "" {
+			locale = strings.ToLower(locale)
+
+			if !strings.Contains(locale, "utf-8") && !strings.Contains(locale, "utf8") {
+				return " -> "
+			}
+
+			break
+		}
+	}
+
+	return " → "
+}
+
+// Returns the size of the file a symlink points to in parentheses, next to the size of
+// the link itself in the size column.
+func linkTargetSize(target string, opts Options) cell {
+	info, err := os.Stat(target)
+
+	if err != nil {
+		return cell{{" (broken)", colorPermWrite}}
+	} else if info.IsDir() {
+		return cell{{" (dir)", nil}}
+	}
+
+	return cell{{" (", nil}, {formatSize(info.Size(), opts), colorFileSize}, {")", nil}}
+}
+
+// Returns the combined size of the files in the whole tree below the directory, and
+// whether every directory in it could be read. Symlinks are counted but not followed.
+func diskUsage(path string) (int64, bool) {
+	files, err := readDir(path)
+
+	if err != nil {
+		return 0, false
+	}
+
+	var size int64
+	complete := true
+
+	for _, file := range files {
+		if !file.IsDir() {
+			size += file.Size()
+			continue
+		}
+
+		subSize, subComplete := diskUsage(filepath.Join(path, file.Name()))
+		size += subSize
+		complete = complete && subComplete
+	}
+
+	return size, complete
+}
+
+// The number of files whose details are looked up at the same time. Most of the time
+// goes into waiting on owner lookups, symlinks and walking directories for --du, so
+// this can exceed the CPU count.
+const lookupWorkers = 8
+
+// A row holds the cells of a single file.
+type row struct {
+	permissions cell
+	octal       cell
+	kind        cell
+	size        cell
+	count       cell
+	owner       cell
+	date        cell
+	name        cell
+	problem     error
+	flagged     bool
+}
+
+// Returns the character --classify puts after a name to tell its type, like ls -F does.
+// Regular files that are not executable get none.
+func classifySuffix(mode os.FileMode) string {
+	switch {
+	case mode.IsDir():
+		return "/"
+	case mode&os.ModeSymlink != 0:
+		return "@"
+	case mode&os.ModeNamedPipe != 0:
+		return "|"
+	case mode&os.ModeSocket != 0:
+		return "="
+	case mode.IsRegular() && mode&0111 != 0:
+		return "*"
+	}
+
+	return ""
+}
+
+// Returns the absolute path the file really lives at with all symlinks resolved, in
+// parentheses. A broken symlink has no real path and gets an empty cell.
+func realpathCell(path string) cell {
+	resolved, err := filepath.EvalSymlinks(path)
+
+	if err != nil {
+		return nil
+	}
+
+	if absolute, err := filepath.Abs(resolved); err == nil {
+		resolved = absolute
+	}
+
+	return cell{{" (" + resolved + ")", colorHidden}}
+}
+
+// Looks up the details of a single file. In strict mode the name of a file whose
+// details could not be read is marked and the problem is returned with the row.
+func buildRow(file os.FileInfo, path string, opts Options) row {
+	ownerName, err := ownerCell(file, opts)
+	fileName := nameCell(file, path, opts)
+	var problem error
+
+	if opts.DimHidden && strings.HasPrefix(file.Name(), ".") {
+		fileName = fileName.withColor(colorHidden)
+	}
+
+	if opts.Realpath {
+		fileName = append(fileName, realpathCell(filepath.Join(path, file.Name()))...)
+	}
+
+	if opts.Attrs && file.Mode()&os.ModeSymlink == 0 {
+		if attributes := fileAttributes(filepath.Join(path, file.Name())); len(attributes) > 0 {
+			fileName = append(fileName.withColor(colorProtected), segment{" [" + strings.Join(attributes, ", ") + "]", colorProtected})
+		}
+	}
+
+	if suffix := classifySuffix(file.Mode()); opts.Classify && suffix != "" {
+		// Right after the name, before the target of a symlink
+		fileName = append(fileName[:1], append(cell{{suffix, nil}}, fileName[1:]...)...)
+	}
+
+	var flagged bool
+
+	if opts.Audit {
+		if findings := auditFile(file, opts); len(findings) > 0 {
+			fileName = append(fileName, auditCell(findings)...)
+			flagged = true
+		}
+	}
+
+	if err != nil && opts.Strict {
+		fileName = append(fileName, segment{" [!err]", colorPermWrite})
+		problem = fmt.Errorf("%s: %v", file.Name(), err)
+	}
+
+	perm := permissionsCell(file.Mode())
+
+	var octal cell
+
+	if opts.Octal {
+		octal = cell{{octalMode(file.Mode()), colorPermOther}}
+	}
+
+	if opts.ACL && file.Mode()&os.ModeSymlink == 0 && hasACL(filepath.Join(path, file.Name())) {
+		perm = append(perm, segment{"+", colorPermOther})
+	}
+
+	var count cell
+
+	if opts.CountRecursive {
+		count = countCell(file, path, opts)
+	}
+
+	return row{
+		permissions: perm,
+		octal:       octal,
+		kind:        typeCell(file.Mode()),
+		size:        sizeCell(file, path, opts),
+		count:       count,
+		owner:       ownerName,
+		date:        dateCell(file.ModTime(), dateLayout(file.ModTime(), opts), opts),
+		name:        fileName,
+		problem:     problem,
+		flagged:     flagged,
+	}
+}
+
+// Returns the git status of a file, the change in the index in green and the one in the
+// work tree in red. Files without changes get a blank cell.
+func gitCell(status string) cell {
+	switch status {
+	case "":
+		return cell{{"  ", nil}}
+	case "??":
+		return cell{{status, colorPermWrite}}
+	case "!!":
+		return cell{{status, colorHidden}}
+	}
+
+	return cell{{status[:1], colorPermExecute}, {status[1:], colorPermWrite}}
+}
+
+// Returns the number of the hard link group of every file sharing its inode with
+// another file in the listing, keyed by the index of the file. The groups are numbered
+// in the order they first show up.
+func hardlinkGroups(files []os.FileInfo) map[int]int {
+	type inode struct{ dev, ino uint64 }
+	entries := map[inode][]int{}
+	var order []inode
+
+	for i, file := range files {
+		// Directories cannot be hard linked, their links are . and .. entries
+		if file.IsDir() {
+			continue
+		}
+
+		dev, ino, ok := fileInode(file)
+
+		if !ok {
+			continue
+		}
+
+		key := inode{dev, ino}
+
+		if _, seen := entries[key]; !seen {
+			order = append(order, key)
+		}
+
+		entries[key] = append(entries[key], i)
+	}
+
+	groups := map[int]int{}
+	number := 0
+
+	for _, key := range order {
+		if len(entries[key]) < 2 {
+			continue
+		}
+
+		number++
+
+		for _, i := range entries[key] {
+			groups[i] = number
+		}
+	}
+
+	return groups
+}
+
+// Looks up the details of all files, spread over a number of workers. Every worker
+// stores its rows at the index of the file, so the order of the files is kept.
+func buildRows(files []os.FileInfo, path string, opts Options) []row {
+	rows := make([]row, len(files))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < lookupWorkers; w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range indexes {
+				rows[i] = buildRow(files[i], path, opts)
+			}
+		}()
+	}
+
+	for i := range files {
+		indexes <- i
+	}
+
+	close(indexes)
+	wg.Wait()
+
+	return rows
+}
+
+// Builds the columns of the listing, one cell per file in each, and returns the
+// problems found while reading the file details along with the number of files flagged
+// by --audit. Like in the other totals the . and .. of --all are not counted.
+func buildColumns(files []os.FileInfo, path string, opts Options) ([]*column, []error, int) {
+	permissions := &column{header: "Permissions"}
+	octal := &column{header: "Octal"}
+	kind := &column{header: "Type", width: 7}
+	size := &column{header: "Size", width: 5, alignRight: opts.SizeAlign == "right"}
+	count := &column{header: "Files", width: 5, alignRight: true}
+	owner := &column{header: "User Group"}
+	date := &column{header: "Date Modified", width: 12, alignRight: true}
+	name := &column{header: "Name"}
+	var problems []error
+	flagged := 0
+
+	if opts.ACL {
+		// Leave room for the + marking files with an ACL
+		permissions.width = 11
+	}
+
+	if opts.TimeStyle == "both" {
+		date.width = maxDateWidth
+	}
+
+	for i, r := range buildRows(files, path, opts) {
+		permissions.add(r.permissions)
+		octal.add(r.octal)
+		kind.add(r.kind)
+		size.add(r.size)
+		count.add(r.count)
+		owner.add(r.owner)
+		date.add(r.date)
+		name.add(r.name)
+
+		if r.problem != nil {
+			problems = append(problems, r.problem)
+		}
+
+		if name := files[i].Name(); r.flagged && name != "." && name != ".." {
+			flagged++
+		}
+	}
+
+	git := &column{header: "Git"}
+
+	if opts.Git && path != "" {
+		// Outside of a work tree the column is left out
+		if statuses, err := gitStatuses(path); err == nil {
+			for _, file := range files {
+				git.add(gitCell(statuses[file.Name()]))
+			}
+		}
+	}
+
+	if opts.GroupHardlinks {
+		for i, group := range hardlinkGroups(files) {
+			name.cells[i] = append(name.cells[i], segment{fmt.Sprintf(" [hardlink group %d]", group), colorHidden})
+		}
+	}
+
+	// Line up the groups, whatever the length of the user names
+	padFirstSegments(owner)
+
+	if opts.PadNames {
+		padFirstSegments(name)
+	}
+
+	columns := []*column{permissions}
+
+	if opts.Octal {
+		columns = append(columns, octal)
+	}
+
+	if opts.TypeColumn {
+		columns = append(columns, kind)
+	}
+
+	columns = append(columns, size)
+
+	if opts.CountRecursive {
+		columns = append(columns, count)
+	}
+
+	if hasOwners {
+		columns = append(columns, owner)
+	}
+
+	columns = append(columns, date)
+
+	if len(git.cells) > 0 {
+		columns = append(columns, git)
+	}
+
+	return append(columns, name), problems, flagged
+}
+
+// Prints the listing and returns the problems found while reading the file details,
+// along with the number of files flagged by --audit.
+func (l *Lister) outputFiles(files []os.FileInfo, path string, opts Options) ([]error, int) {
+	// A panic halfway through a row would leave the terminal in the colour of the cell.
+	// The reset goes after whatever was written so far, wherever that is written to.
+	defer func() {
+		if r := recover(); r != nil {
+			if !color.NoColor {
+				fmt.Fprint(l.Output, colorReset)
+			}
+
+			panic(r)
+		}
+	}()
+
+	start := time.Now()
+
+	if !isLong(opts) {
+		printShort(l.Output, files, path, opts)
+		l.stageDone("render", start, opts)
+
+		return nil, 0
+	}
+
+	columns, problems, flagged := buildColumns(files, path, opts)
+	start = l.stageDone("lookup", start, opts)
+
+	if opts.AutoColumns {
+		columns = dropUniformColumns(columns)
+	}
+
+	if opts.FitWidth && !opts.Markdown && !opts.HTML {
+		if opts.Width > 0 {
+			columns = fitColumns(columns, strings.Repeat(" ", opts.Spacing), opts.Width)
+		}
+	}
+
+	if opts.DescribeColumns {
+		fitToCells(columns)
+
+		if err := outputColumnsJSON(l.Output, columns); err != nil {
+			problems = append(problems, err)
+		}
+	} else if opts.Markdown {
+		printMarkdown(l.Output, columns)
+	} else if opts.HTML {
+		printHTML(l.Output, columns)
+	} else if opts.Border {
+		fitToCells(columns)
+		printBordered(l.Output, columns, opts.Header)
+	} else {
+		fitToCells(columns)
+
+		if opts.Header {
+			outputHeader(l.Output, columns, strings.Repeat(" ", opts.Spacing))
+		}
+
+		printColumns(l.Output, columns, strings.Repeat(" ", opts.Spacing), headings(files, opts))
+	}
+
+	l.stageDone("render", start, opts)
+
+	return problems, flagged
+}
+
+// Returns whether the files are listed in columns with all their details. Tables always
+// have them, the lines of the terminal with --long or any flag that adds to the details,
+// as those would go unseen with only the names.
+func isLong(opts Options) bool {
+	return opts.Long || opts.Markdown || opts.HTML || opts.Border || opts.DescribeColumns ||
+		opts.Header || opts.Octal || opts.TypeColumn || opts.Git || opts.CountRecursive ||
+		opts.ACL || opts.Attrs || opts.Audit || opts.Strict || opts.DU || opts.Realpath ||
+		opts.LinkDetail || opts.GroupHardlinks || opts.AutoColumns || opts.FitWidth
+}
+
+// Prints only the names of the files, like ls without -l. Above --grid-threshold
+// entries they are laid out in as many columns as fit the terminal.
+func printShort(w io.Writer, files []os.FileInfo, path string, opts Options) {
+	names := shortNameCells(files, path, opts)
+
+	if len(names) > opts.GridThreshold {
+		printGrid(w, names, gridWidth(opts), strings.Repeat(" ", opts.Spacing), opts.PadNames)
+		return
+	}
+
+	// With --pad-names the indicators of --classify line up, in the grid only within a
+	// column
+	if opts.PadNames {
+		padFirstSegments(&column{cells: names})
+	}
+
+	for _, name := range names {
+		name.print(w)
+		fmt.Fprintln(w)
+	}
+}
+
+// Returns the names of the files for the grid, coloured like in the columns but
+// without the targets of symlinks.
+func shortNameCells(files []os.FileInfo, path string, opts Options) []cell {
+	cells := make([]cell, len(files))
+
+	for i, file := range files {
+		name := nameCell(file, path, opts)[:1]
+
+		if opts.DimHidden && strings.HasPrefix(file.Name(), ".") {
+			name = name.withColor(colorHidden)
+		}
+
+		if suffix := classifySuffix(file.Mode()); opts.Classify && suffix != "" {
+			name = append(name, segment{suffix, nil})
+		}
+
+		cells[i] = name
+	}
+
+	return cells
+}
+
+// Returns the width to lay out the grid in, 80 columns when it is not known.
+func gridWidth(opts Options) int {
+	if opts.Width > 0 {
+		return opts.Width
+	}
+
+	return 80
+}
+
+// Returns the headings to print above the rows starting a new group with --group-by-day
+// or --group-by-extension, keyed by the index of the row.
+func headings(files []os.FileInfo, opts Options) map[int]string {
+	headings := map[int]string{}
+
+	if !opts.GroupByDay {
+		if opts.GroupByExtension && opts.Sort == "" {
+			return extensionHeadings(files)
+		}
+
+		return headings
+	}
+
+	for i, file := range files {
+		day := file.ModTime().Format("Monday 2 January 2006")
+
+		if i == 0 || day != files[i-1].ModTime().Format("Monday 2 January 2006") {
+			headings[i] = day
+		}
+	}
+
+	return headings
+}
+
+// Returns a heading for every group of files with the same extension. The directories
+// sort before the files and go without one.
+func extensionHeadings(files []os.FileInfo) map[int]string {
+	headings := map[int]string{}
+	previous := ""
+
+	for i, file := range files {
+		if file.IsDir() {
+			continue
+		}
+
+		ext := filepath.Ext(file.Name())
+
+		if len(headings) == 0 || ext != previous {
+			headings[i] = "*" + ext
+
+			if ext == "" {
+				headings[i] = "no extension"
+			}
+		}
+
+		previous = ext
+	}
+
+	return headings
+}
+
+// Logs how long a stage of the listing took with --stats, and returns the
+// time the next stage starts at.
+func (l *Lister) stageDone(stage string, start time.Time, opts Options) time.Time {
+	if opts.Stats {
+		l.logf("stats: %s %v", stage, time.Since(start))
+	}
+
+	return time.Now()
+}
+
+// Prints the header line above the listing with the listed directory and the git branch
+// it is on, when either is asked for.
+func printBanner(w io.Writer, path string, opts Options) {
+	var banner []string
+
+	if opts.Banner {
+		banner = append(banner, path)
+	}
+
+	if opts.GitBranch {
+		if branch, err := gitBranch(path); err == nil {
+			banner = append(banner, "on "+branch)
+		}
+	}
+
+	if len(banner) > 0 {
+		colorHeader.Fprintln(w, strings.Join(banner, " "))
+	}
+}
+
+// Prints the name of every column above it. Columns narrower than their name are
+// widened first, so the rows printed after line up with the header.
+func outputHeader(w io.Writer, columns []*column, spacer string) {
+	for _, col := range columns {
+		if displayWidth(col.header) > col.width {
+			col.width = displayWidth(col.header)
+		}
+	}
+
+	for i, col := range columns {
+		if i == len(columns)-1 {
+			colorHeader.Fprint(w, col.header)
+			break
+		}
+
+		padding := strings.Repeat(" ", col.width-displayWidth(col.header))
+
+		if col.alignRight {
+			fmt.Fprint(w, padding)
+			colorHeader.Fprint(w, col.header)
+		} else {
+			colorHeader.Fprint(w, col.header)
+			fmt.Fprint(w, padding)
+		}
+
+		fmt.Fprint(w, spacer)
+	}
+
+	fmt.Fprintln(w)
+}
+
+// Lists a single path given on the command line. A directory lists its entries, a
+// glob the files it matches and a file only itself.
+func (l *Lister) listPath(path string, filters []filter, opts Options) error {
+	clearPath, err := filepath.Abs(path)
+
+	if err != nil {
+		return err
+	}
+
+	printBanner(l.Output, clearPath, opts)
+
+	if opts.Tree {
+		if err := l.outputTree(clearPath, filters, opts); err != nil {
+			return err
+		}
+
+		if opts.RecursiveTotal {
+			l.printRecursiveTotal(clearPath, filters, opts)
+		}
+
+		return nil
+	}
+
+	if opts.Flat {
+		if err := l.outputFlat(clearPath, filters, opts); err != nil {
+			return err
+		}
+
+		if opts.RecursiveTotal {
+			l.printRecursiveTotal(clearPath, filters, opts)
+		}
+
+		return nil
+	}
+
+	if opts.Recursive && isDirectory(clearPath) {
+		if err := l.outputRecursive(clearPath, filters, opts); err != nil {
+			return err
+		}
+
+		if opts.RecursiveTotal {
+			fmt.Fprintln(l.Output)
+			l.printRecursiveTotal(clearPath, filters, opts)
+		}
+
+		return nil
+	}
+
+	dir, err := l.readListing(path, filters, opts)
+
+	if err != nil {
+		return err
+	}
+
+	l.printListing(dir)
+
+	return nil
+}
+
+// Lists the files given on the command line together, like the entries of a single
+// directory. The ones that can not be read are warned about and left out. Returns
+// whether any of them was listed.
+func (l *Lister) listFiles(paths []string, filters []filter, opts Options) bool {
+	var files []os.FileInfo
+	start := time.Now()
+
+	for _, path := range paths {
+		file, err := readFile(path)
+
+		if err != nil {
+			l.warn(err)
+			continue
+		}
+
+		files = append(files, file...)
+	}
+
+	if len(files) == 0 {
+		return false
+	}
+
+	l.printListing(l.sortListing(files, "", 0, filters, opts, start))
+
+	return true
+}
+
+// Prints the entries of a listing along with the totals and summaries asked for, with the
+// options of the listing as the .gutconfig of its directory may have changed them.
+func (l *Lister) printListing(dir listing) {
+	files := dir.files
+	clearPath, opts, filters := dir.path, dir.opts, dir.filters
+	l.listed += len(files)
+
+	listed := files
+
+	// Only a directory read has a . and .., they are not counted in any of the totals but
+	// are filtered and limited like the other entries
+	if opts.All && clearPath != "" && !opts.Peek {
+		listed = append(filterFiles(l.dotEntries(clearPath), filters), files...)
+
+		if opts.Limit > 0 && len(listed) > opts.Limit {
+			listed = listed[:opts.Limit]
+		}
+	}
+
+	problems, flagged := l.outputFiles(listed, clearPath, opts)
+
+	if opts.FilesTotal {
+		printFilesTotal(l.Output, files, opts)
+	}
+
+	if opts.Total {
+		printTotal(l.Output, files, clearPath, opts)
+	}
+
+	if opts.RecursiveTotal {
+		l.printRecursiveTotal(clearPath, filters, opts)
+	}
+
+	if opts.Audit {
+		printAuditSummary(l.Output, flagged, len(files))
+	}
+
+	if opts.MatchStats {
+		fmt.Fprintf(l.Output, "showing %d of %d\n", len(files), dir.read)
+	}
+
+	if hint := suggestion(files, dir.hidden, opts); opts.Suggest && hint != "" && !opts.Quiet {
+		l.logf("%s", hint)
+	}
+
+	for _, problem := range problems {
+		l.warn(problem)
+	}
+}
+
+// A listing holds the entries of a path that are left to list after reading, sorting
+// and filtering them, along with the options and filters of the directory.
+type listing struct {
+	files   []os.FileInfo
+	path    string
+	read    int
+	hidden  int
+	opts    Options
+	filters []filter
+}
+
+// Reads the entries to list for a path given on the command line. The path of the
+// listing is the directory the entries are in, blank for a file or the matches of a
+// glob as they are named by their path.
+func (l *Lister) readListing(path string, filters []filter, opts Options) (listing, error) {
+	clearPath, err := filepath.Abs(path)
+
+	if err != nil {
+		return listing{}, err
+	}
+
+	if isDirectory(clearPath) && !isGlob(path) && !opts.Peek {
+		opts, filters = l.dirOptions(clearPath, opts, filters)
+	}
+
+	var files []os.FileInfo
+	var hidden int
+	start := time.Now()
+
+	if isGlob(path) {
+		// The matches are named by their path, so they are not relative to a directory
+		files, err = globFiles(path)
+		clearPath = ""
+	} else if opts.Peek {
+		files, err = readArchive(clearPath)
+	} else if !isDirectory(clearPath) {
+		// A file is named as given, like the matches of a glob
+		files, err = readFile(path)
+		clearPath = ""
+	} else {
+		files, err = readDirWithTimeout(clearPath, opts.Timeout)
+		hidden = len(files)
+		files = removeHidden(files, opts)
+		hidden -= len(files)
+	}
+
+	if err != nil {
+		return listing{}, err
+	}
+
+	return l.sortListing(files, clearPath, hidden, filters, opts, start), nil
+}
+
+// Sorts, filters and limits the entries read for a listing, the read having started at
+// the given time for --stats.
+func (l *Lister) sortListing(files []os.FileInfo, clearPath string, hidden int, filters []filter, opts Options, start time.Time) listing {
+	if opts.Limit == 0 && opts.WarnEntries > 0 && len(files) > opts.WarnEntries && !opts.Quiet {
+		// Only a hint, so it does not count towards the exit code like a warning
+		l.logf("%d entries; use --limit to truncate", len(files))
+	}
+
+	start = l.stageDone("read", start, opts)
+	sortFiles(files, clearPath, opts)
+	l.stageDone("sort", start, opts)
+
+	read := len(files)
+	files = filterFiles(files, filters)
+
+	if opts.Limit > 0 && len(files) > opts.Limit {
+		files = files[:opts.Limit]
+	}
+
+	return listing{files, clearPath, read, hidden, opts, filters}
+}
+
+// Returns whether the path is a directory or a symlink to one.
+func isDirectory(path string) bool {
+	file, err := os.Stat(path)
+
+	return err == nil && file.IsDir()
+}
+
+// Returns a file given on the command line as the only entry to list.
+func readFile(path string) ([]os.FileInfo, error) {
+	file, err := os.Lstat(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return []os.FileInfo{namedEntry{file, path}}, nil
+}
+
+// Reads the entries of a directory in the order the file system returns them. Unlike
+// ioutil.ReadDir the entries are not sorted by name, the ordering is left to the caller.
+func readDir(path string) ([]os.FileInfo, error) {
+	dir, err := os.Open(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer dir.Close()
+
+	return dir.Readdir(-1)
+}
+
+// Reads the directory for readDirWithTimeout and the flat walk, replaced in the tests by a
+// read that never ends or one counting the reads.
+var dirReader = readDir
+
+// Reads the directory like readDir, which also stats every entry, but gives up once it
+// takes longer than the timeout so a hanging network mount does not hang gut as well.
+func readDirWithTimeout(path string, timeout time.Duration) ([]os.FileInfo, error) {
+	if timeout <= 0 {
+		return readDir(path)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	type result struct {
+		files []os.FileInfo
+		err   error
+	}
+
+	// Buffered so the read can still finish after we stopped waiting for it
+	done := make(chan result, 1)
+
+	go func() {
+		files, err := dirReader(path)
+		done <- result{files, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.files, r.err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("reading %s timed out after %v", path, timeout)
+	}
+}
+
+// Returns whether the path is a pattern to expand rather than a plain path. A path that
+// exists is always listed as it is, even with a * or [ in its name.
+func isGlob(path string) bool {
+	if _, err := os.Lstat(path); err == nil {
+		return false
+	}
+
+	return strings.ContainsAny(path, "*?[")
+}
+
+// Returns the files matching a glob pattern, named by the path they matched with.
+func globFiles(pattern string) ([]os.FileInfo, error) {
+	matches, err := filepath.Glob(pattern)
+
+	if err != nil {
+		return nil, err
+	} else if len(matches) == 0 {
+		return nil, errors.New("no files match " + pattern)
+	}
+
+	var files []os.FileInfo
+
+	for _, match := range matches {
+		file, err := os.Lstat(match)
+
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, namedEntry{file, match})
+	}
+
+	return files, nil
+}
+
+// Prints the number and combined size of the regular files, and the number of symlinks
+// unless they are counted as files. Directories and other special files are left out.
+func printFilesTotal(w io.Writer, files []os.FileInfo, opts Options) {
+	total := summarize(files, opts.SymlinksAsFiles)
+
+	fmt.Fprint(w, strconv.Itoa(total.Files)+" files, ")
+
+	if total.Symlinks > 0 {
+		fmt.Fprint(w, strconv.Itoa(total.Symlinks)+" symlinks, ")
+	}
+
+	colorFileSize.Fprint(w, formatSize(total.Size, opts))
+	fmt.Fprintln(w, " total")
+}
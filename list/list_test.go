@@ -0,0 +1,1194 @@
+package list
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// The modification time of the entries of a fixture, long enough ago to show the year.
+var fixtureTime = time.Date(2020, time.March, 1, 12, 0, 0, 0, time.Local)
+
+// Creates the entries in a new temporary directory and returns its path. A name ending
+// in a slash is a directory, any other name a file holding the given content. All of
+// them are modified at fixtureTime.
+func fixture(t testing.TB, entries map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+
+	for name, content := range entries {
+		path := filepath.Join(dir, name)
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+
+		if err == nil && strings.HasSuffix(name, "/") {
+			err = os.MkdirAll(path, 0755)
+		} else if err == nil {
+			err = ioutil.WriteFile(path, []byte(content), 0644)
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	touch(t, dir, fixtureTime)
+
+	return dir
+}
+
+// Sets the modification time of everything below the directory but the symlinks.
+func touch(t testing.TB, dir string, modTime time.Time) {
+	t.Helper()
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.Mode()&os.ModeSymlink != 0 {
+			return err
+		}
+
+		return os.Chtimes(path, modTime, modTime)
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Creates a symlink in the directory pointing to the target.
+func symlink(t testing.TB, dir string, target string, name string) {
+	t.Helper()
+
+	if err := os.Symlink(target, filepath.Join(dir, name)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Returns the listing of the path with the default options changed by set.
+func render(t testing.TB, path string, set func(opts *Options)) string {
+	t.Helper()
+
+	opts := DefaultOptions()
+
+	if set != nil {
+		set(&opts)
+	}
+
+	var out bytes.Buffer
+
+	if err := Render(&out, path, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	return out.String()
+}
+
+// Returns the text in the colour, as it is printed with the colours on.
+func colored(c *color.Color, text string) string {
+	defer func(noColor bool) { color.NoColor = noColor }(color.NoColor)
+	color.NoColor = false
+
+	var out bytes.Buffer
+	c.Fprint(&out, text)
+
+	return out.String()
+}
+
+// Returns the lines of the output.
+func lines(output string) []string {
+	return strings.Split(strings.TrimSuffix(output, "\n"), "\n")
+}
+
+// Returns the line of the output with the name at its end.
+func lineOf(t testing.TB, output string, name string) string {
+	t.Helper()
+
+	for _, line := range lines(output) {
+		if line == name || strings.HasSuffix(line, " "+name) || strings.Contains(line, " "+name+" ") {
+			return line
+		}
+	}
+
+	t.Fatalf("no line for %s in:\n%s", name, output)
+
+	return ""
+}
+
+// Returns the fields of the line of the output for the name.
+func fieldsOf(t testing.TB, output string, name string) []string {
+	t.Helper()
+
+	return strings.Fields(lineOf(t, output, name))
+}
+
+func TestFriendlySize(t *testing.T) {
+	tests := []struct {
+		size int64
+		set  func(opts *Options)
+		want string
+	}{
+		{1000, func(opts *Options) {}, "1000"},
+		{1000, func(opts *Options) { opts.Human = true }, "1000"},
+		{1000, func(opts *Options) { opts.SI = true }, "1k"},
+		{1536, func(opts *Options) { opts.Human = true }, "1.5Ki"},
+		{1048575, func(opts *Options) { opts.Human = true }, "1Mi"},
+		{999999, func(opts *Options) { opts.SI = true }, "1M"},
+		{3 << 30, func(opts *Options) { opts.Human = true }, "3Gi"},
+		{5 << 40, func(opts *Options) { opts.Human = true }, "5Ti"},
+		{5 << 50, func(opts *Options) { opts.Human = true }, "5120Ti"},
+		{512 << 10, func(opts *Options) { opts.SmartUnits = true }, "512Ki"},
+		{1536 << 20, func(opts *Options) { opts.SmartUnits = true }, "1.5Gi"},
+		{1, func(opts *Options) { opts.LongUnits = true }, "1 byte"},
+		{2, func(opts *Options) { opts.LongUnits = true }, "2 bytes"},
+		{1536 << 20, func(opts *Options) { opts.LongUnits = true }, "1.5 gibibytes"},
+		{2000, func(opts *Options) { opts.LongUnits, opts.SI = true, true }, "2 kilobytes"},
+	}
+
+	for _, test := range tests {
+		opts := DefaultOptions()
+		test.set(&opts)
+
+		if got := formatSize(test.size, opts); got != test.want {
+			t.Errorf("formatSize(%d) = %q, want %q", test.size, got, test.want)
+		}
+	}
+}
+
+func TestHuman(t *testing.T) {
+	dir := fixture(t, map[string]string{"big": strings.Repeat("x", 2048), "sub/": ""})
+
+	tests := []struct {
+		human bool
+		size  string
+		total string
+	}{
+		{false, "2048", "1 files, 1 dirs, 2048 total"},
+		{true, "2Ki", "1 files, 1 dirs, 2Ki total"},
+	}
+
+	for _, test := range tests {
+		out := render(t, dir, func(opts *Options) { opts.Long, opts.Total, opts.Human = true, true, test.human })
+
+		if size := fieldsOf(t, out, "big")[1]; size != test.size {
+			t.Errorf("human %v: size %q, want %q", test.human, size, test.size)
+		}
+
+		if total := lines(out)[2]; total != test.total {
+			t.Errorf("human %v: total %q, want %q", test.human, total, test.total)
+		}
+	}
+}
+
+func TestShortAndLong(t *testing.T) {
+	dir := fixture(t, map[string]string{"a": "", "b": "", "sub/": ""})
+
+	tests := []struct {
+		name string
+		set  func(opts *Options)
+		want []string
+	}{
+		{"short", func(opts *Options) {}, []string{"sub", "a", "b"}},
+		{"long", func(opts *Options) { opts.Long = true }, nil},
+		{"implied by --octal", func(opts *Options) { opts.Octal = true }, nil},
+		{"implied by --header", func(opts *Options) { opts.Header = true }, nil},
+		{"implied by --strict", func(opts *Options) { opts.Strict = true }, nil},
+	}
+
+	for _, test := range tests {
+		out := lines(render(t, dir, test.set))
+
+		if test.name == "implied by --header" {
+			out = out[1:]
+		}
+
+		if test.want != nil {
+			if strings.Join(out, "\n") != strings.Join(test.want, "\n") {
+				t.Errorf("%s: got %q, want %q", test.name, out, test.want)
+			}
+
+			continue
+		}
+
+		for _, line := range out {
+			if !strings.Contains(line, "rw") {
+				t.Errorf("%s: line without permissions: %q", test.name, line)
+			}
+		}
+	}
+}
+
+func TestGridThreshold(t *testing.T) {
+	dir := fixture(t, map[string]string{"a": "", "b": "", "c": "", "d": ""})
+
+	tests := []struct {
+		threshold int
+		want      []string
+	}{
+		{4, []string{"a", "b", "c", "d"}},
+		{3, []string{"a  b  c  d"}},
+	}
+
+	for _, test := range tests {
+		out := lines(render(t, dir, func(opts *Options) { opts.GridThreshold = test.threshold }))
+
+		if strings.Join(out, "\n") != strings.Join(test.want, "\n") {
+			t.Errorf("threshold %d: got %q, want %q", test.threshold, out, test.want)
+		}
+	}
+}
+
+func TestGridWidth(t *testing.T) {
+	dir := fixture(t, map[string]string{"aaaa": "", "bbbb": "", "cccc": "", "dddd": ""})
+
+	out := lines(render(t, dir, func(opts *Options) { opts.GridThreshold, opts.Width = 0, 10 }))
+	want := []string{"aaaa  cccc", "bbbb  dddd"}
+
+	if strings.Join(out, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q, want %q", out, want)
+	}
+}
+
+func TestAll(t *testing.T) {
+	dir := fixture(t, map[string]string{".hidden": "", "shown": "", "sub/": ""})
+
+	tests := []struct {
+		name   string
+		set    func(opts *Options)
+		want   []string
+		listed int
+	}{
+		{"without all", func(opts *Options) {}, []string{"sub", "shown"}, 2},
+		{"all", func(opts *Options) { opts.All = true }, []string{".", "..", "sub", ".hidden", "shown"}, 3},
+		{"files only", func(opts *Options) { opts.All, opts.FilesOnly = true, true }, []string{".hidden", "shown"}, 2},
+		{"only regular", func(opts *Options) { opts.All, opts.OnlyRegular = true, true }, []string{".hidden", "shown"}, 2},
+		{"regexp", func(opts *Options) { opts.All, opts.Regexp = true, "^s" }, []string{"sub", "shown"}, 2},
+		{"no match", func(opts *Options) { opts.All, opts.Regexp = true, "zzz" }, nil, 0},
+		{"regexp matching the dots", func(opts *Options) { opts.All, opts.Regexp = true, `^\.\.?$` }, []string{".", ".."}, 0},
+		{"limit", func(opts *Options) { opts.All, opts.Limit = true, 3 }, []string{".", "..", "sub"}, 3},
+	}
+
+	for _, test := range tests {
+		var out bytes.Buffer
+		lister := &Lister{Output: &out}
+		opts := DefaultOptions()
+		test.set(&opts)
+
+		if err := lister.Render(dir, opts); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := strings.Fields(out.String()); strings.Join(got, " ") != strings.Join(test.want, " ") {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+
+		// The dot entries do not count as listed, for --fail-if-empty
+		if lister.Listed() != test.listed {
+			t.Errorf("%s: listed %d entries, want %d", test.name, lister.Listed(), test.listed)
+		}
+	}
+}
+
+func TestFilesTotal(t *testing.T) {
+	dir := fixture(t, map[string]string{"a.txt": "12345", "b.txt": "123", "sub/": "", "sub/c.txt": "1234567890"})
+	symlink(t, dir, "a.txt", "link")
+
+	mkfifo(t, filepath.Join(dir, "fifo"))
+
+	tests := []struct {
+		name string
+		set  func(opts *Options)
+		want string
+	}{
+		{"regular files only", func(opts *Options) {}, "2 files, 1 symlinks, 8 total"},
+		{"symlinks as files", func(opts *Options) { opts.SymlinksAsFiles = true }, "3 files, 13 total"},
+	}
+
+	for _, test := range tests {
+		out := render(t, dir, func(opts *Options) {
+			opts.FilesTotal = true
+			test.set(opts)
+		})
+
+		if got := lines(out)[len(lines(out))-1]; got != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestTotal(t *testing.T) {
+	dir := fixture(t, map[string]string{"a": "1234", "b.go": "12", "sub/": "", "sub/deep": "12345678"})
+
+	tests := []struct {
+		name string
+		set  func(opts *Options)
+		want string
+	}{
+		{"listing", func(opts *Options) {}, "2 files, 1 dirs, 6 total"},
+		{"filtered", func(opts *Options) { opts.Extensions = "go" }, "1 files, 1 dirs, 2 total"},
+	}
+
+	for _, test := range tests {
+		out := render(t, dir, func(opts *Options) {
+			opts.Total = true
+			test.set(opts)
+		})
+
+		if got := lines(out)[len(lines(out))-1]; got != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestAutoColumns(t *testing.T) {
+	dir := fixture(t, map[string]string{"one/": "", "two/": ""})
+
+	out := render(t, dir, func(opts *Options) { opts.AutoColumns = true })
+
+	if fields := fieldsOf(t, out, "one"); len(fields) != 1 {
+		t.Errorf("uniform columns kept: %q", fields)
+	}
+
+	touch(t, filepath.Join(dir, "two"), fixtureTime.Add(-48*time.Hour))
+	out = render(t, dir, func(opts *Options) { opts.AutoColumns = true })
+
+	if fields := fieldsOf(t, out, "one"); strings.Contains(lineOf(t, out, "one"), "-") || len(fields) < 2 {
+		t.Errorf("size column kept or date dropped: %q", fields)
+	}
+}
+
+func TestNoAccess(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can look into any directory")
+	}
+
+	dir := fixture(t, map[string]string{"locked/": "", "locked/file": ""})
+	symlink(t, dir, filepath.Join(dir, "locked", "file"), "link")
+
+	if err := os.Chmod(filepath.Join(dir, "locked"), 0); err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.Chmod(filepath.Join(dir, "locked"), 0755)
+
+	out := render(t, dir, func(opts *Options) { opts.Long, opts.ASCIIArrow = true, true })
+
+	if want := "link -> " + filepath.Join(dir, "locked", "file") + " [no access]"; !strings.Contains(out, want) {
+		t.Errorf("%q not in:\n%s", want, out)
+	}
+}
+
+func TestBrokenSymlink(t *testing.T) {
+	dir := fixture(t, map[string]string{})
+	symlink(t, dir, "missing", "dangling")
+
+	tests := []struct {
+		name string
+		set  func(opts *Options)
+		want string
+	}{
+		{"missing target", func(opts *Options) {}, "dangling -> [target missing]"},
+		{"link detail", func(opts *Options) { opts.LinkDetail = true }, "dangling -> missing (broken)"},
+	}
+
+	for _, test := range tests {
+		out := render(t, dir, func(opts *Options) {
+			opts.Long, opts.ASCIIArrow = true, true
+			test.set(opts)
+		})
+
+		if !strings.HasSuffix(lineOf(t, out, "dangling"), test.want) {
+			t.Errorf("%s: %q does not end in %q", test.name, lineOf(t, out, "dangling"), test.want)
+		}
+	}
+
+	out := render(t, dir, func(opts *Options) { opts.Long, opts.Color = true, "always" })
+
+	if want := colored(colorPermWrite, "dangling"); !strings.Contains(out, want) {
+		t.Errorf("broken link not coloured red: %q", out)
+	}
+}
+
+func TestSymlinkToDirectory(t *testing.T) {
+	dir := fixture(t, map[string]string{"target/": ""})
+	symlink(t, dir, "target", "link")
+
+	out := render(t, dir, func(opts *Options) { opts.Long, opts.Color = true, "always" })
+
+	if want := colored(colorDirName, filepath.Join(dir, "target")); !strings.Contains(out, want) {
+		t.Errorf("target of the link not coloured as a directory: %q", out)
+	}
+}
+
+func TestStrict(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("only root can give a file an owner without a name")
+	}
+
+	dir := fixture(t, map[string]string{"orphan": "", "owned": ""})
+
+	if err := os.Chown(filepath.Join(dir, "orphan"), 4242, 4242); err != nil {
+		t.Fatal(err)
+	}
+
+	var problems []error
+	var out bytes.Buffer
+	opts := DefaultOptions()
+	opts.Strict = true
+	lister := &Lister{Output: &out, Warn: func(err error) { problems = append(problems, err) }}
+
+	if err := lister.Render(dir, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	if line := lineOf(t, out.String(), "orphan"); !strings.HasSuffix(line, "orphan [!err]") || !strings.Contains(line, "4242") {
+		t.Errorf("orphan not marked with its numeric owner: %q", line)
+	}
+
+	if strings.Contains(lineOf(t, out.String(), "owned"), "[!err]") {
+		t.Errorf("owned file marked: %q", out.String())
+	}
+
+	if len(problems) != 1 {
+		t.Errorf("got problems %v, want one", problems)
+	}
+}
+
+func TestCountRecursive(t *testing.T) {
+	dir := fixture(t, map[string]string{
+		"tree/a": "", "tree/sub/b": "", "tree/sub/deeper/c": "", "file": "",
+		"tree/.hidden": "", "tree/.git/d": "", "tree/.git/e": "",
+	})
+
+	tests := []struct {
+		depth int
+		all   bool
+		want  string
+	}{
+		{0, false, "3"},
+		{2, false, "2"},
+		{0, true, "6"},
+		{2, true, "5"},
+	}
+
+	for _, test := range tests {
+		out := render(t, dir, func(opts *Options) { opts.CountRecursive, opts.Depth, opts.All = true, test.depth, test.all })
+
+		if count := fieldsOf(t, out, "tree")[2]; count != test.want {
+			t.Errorf("depth %d, all %v: count %q, want %q", test.depth, test.all, count, test.want)
+		}
+
+		if count := fieldsOf(t, out, "file")[2]; count != "-" {
+			t.Errorf("depth %d, all %v: file count %q, want -", test.depth, test.all, count)
+		}
+	}
+}
+
+func TestDimHidden(t *testing.T) {
+	dir := fixture(t, map[string]string{".gitignore": "", "main.go": ""})
+
+	out := render(t, dir, func(opts *Options) { opts.All, opts.DimHidden, opts.Color = true, true, "always" })
+
+	if !strings.Contains(out, colored(colorHidden, ".gitignore")) {
+		t.Errorf(".gitignore not dim: %q", out)
+	}
+
+	if strings.Contains(out, colored(colorHidden, "main.go")) {
+		t.Errorf("main.go dim: %q", out)
+	}
+}
+
+func TestNoTrailingSpaces(t *testing.T) {
+	dir := fixture(t, map[string]string{"a": "1", "bbbbbbbbbbbbbbbbbbbbbbbb": "", "c/": "", "d": "", "e": "", "f": "", "g": "", "h": "", "i": ""})
+
+	tests := []struct {
+		name string
+		set  func(opts *Options)
+	}{
+		{"grid", func(opts *Options) { opts.Width = 40 }},
+		{"long", func(opts *Options) { opts.Long = true }},
+		{"header", func(opts *Options) { opts.Header = true }},
+		{"padded names", func(opts *Options) { opts.PadNames, opts.Classify = true, true }},
+		{"markdown", func(opts *Options) { opts.Markdown = true }},
+	}
+
+	for _, test := range tests {
+		for _, line := range lines(render(t, dir, test.set)) {
+			if strings.TrimRight(line, " ") != line {
+				t.Errorf("%s: trailing whitespace in %q", test.name, line)
+			}
+		}
+	}
+}
+
+func TestPadNames(t *testing.T) {
+	dir := fixture(t, map[string]string{"short/": "", "much-longer/": "", "mid/": ""})
+
+	out := render(t, dir, func(opts *Options) { opts.PadNames, opts.Classify = true, true })
+	column := -1
+
+	for _, line := range lines(out) {
+		if i := strings.Index(line, "/"); column == -1 {
+			column = i
+		} else if i != column {
+			t.Errorf("indicator at %d instead of %d in %q", i, column, line)
+		}
+	}
+}
+
+func TestClassify(t *testing.T) {
+	dir := fixture(t, map[string]string{"dir/": "", "plain": "", "run": ""})
+	symlink(t, dir, "plain", "link")
+
+	if err := os.Chmod(filepath.Join(dir, "run"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	mkfifo(t, filepath.Join(dir, "pipe"))
+
+	out := render(t, dir, func(opts *Options) { opts.Classify = true })
+	want := []string{"dir/", "link@", "pipe|", "plain", "run*"}
+
+	if strings.Join(lines(out), "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q, want %q", lines(out), want)
+	}
+
+	out = render(t, dir, func(opts *Options) { opts.Classify, opts.Color = true, "always" })
+
+	if !strings.Contains(out, colored(colorDirName, "dir")+"/") {
+		t.Errorf("indicator coloured or directory not bold blue: %q", out)
+	}
+}
+
+func TestFast(t *testing.T) {
+	dir := fixture(t, map[string]string{"file": ""})
+	symlink(t, dir, "./file", "link")
+
+	out := render(t, dir, func(opts *Options) { opts.Long, opts.Fast, opts.ASCIIArrow = true, true, true })
+	uid, gid := strconv.Itoa(os.Getuid()), strconv.Itoa(os.Getgid())
+
+	if fields := fieldsOf(t, out, "file"); fields[2] != uid || fields[3] != gid {
+		t.Errorf("owner %q, want the ids %s %s", fields[2:4], uid, gid)
+	}
+
+	if !strings.HasSuffix(lineOf(t, out, "link"), "link -> ./file") {
+		t.Errorf("target of the link not as stored: %q", lineOf(t, out, "link"))
+	}
+}
+
+func BenchmarkFast(b *testing.B) {
+	entries := map[string]string{}
+
+	for i := 0; i < 500; i++ {
+		entries["file"+strconv.Itoa(i)] = ""
+	}
+
+	dir := fixture(b, entries)
+
+	for i := 0; i < 100; i++ {
+		symlink(b, dir, "file"+strconv.Itoa(i), "link"+strconv.Itoa(i))
+	}
+
+	files, err := readDir(dir)
+
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for _, fast := range []bool{false, true} {
+		opts := DefaultOptions()
+		opts.Fast = fast
+
+		b.Run("fast="+strconv.FormatBool(fast), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				buildRows(files, dir, opts)
+			}
+		})
+	}
+}
+
+func TestBuildRowsOrder(t *testing.T) {
+	entries := map[string]string{}
+
+	for i := 0; i < 200; i++ {
+		entries["file"+strconv.Itoa(i)] = strings.Repeat("x", i)
+	}
+
+	dir := fixture(t, entries)
+	files, err := readDir(dir)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, r := range buildRows(files, dir, DefaultOptions()) {
+		if r.name.String() != files[i].Name() || r.size.String() != strconv.FormatInt(files[i].Size(), 10) {
+			t.Fatalf("row %d is %s of %s, want %s", i, r.name, r.size, files[i].Name())
+		}
+	}
+}
+
+func BenchmarkBuildRows(b *testing.B) {
+	entries := map[string]string{}
+
+	for i := 0; i < 1000; i++ {
+		entries["file"+strconv.Itoa(i)] = ""
+	}
+
+	dir := fixture(b, entries)
+	files, err := readDir(dir)
+
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	opts := DefaultOptions()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		buildRows(files, dir, opts)
+	}
+}
+
+func TestDirMtimeInSize(t *testing.T) {
+	dir := fixture(t, map[string]string{"sub/": "", "file": "123"})
+	touch(t, filepath.Join(dir, "sub"), time.Now().Add(-3*time.Hour))
+
+	out := render(t, dir, func(opts *Options) { opts.Long, opts.DirMtimeInSize = true, true })
+
+	if size := fieldsOf(t, out, "sub")[1]; size != "3h" {
+		t.Errorf("directory size %q, want 3h", size)
+	}
+
+	if size := fieldsOf(t, out, "file")[1]; size != "3" {
+		t.Errorf("file size %q, want 3", size)
+	}
+}
+
+func TestBlankSymlinkSize(t *testing.T) {
+	dir := fixture(t, map[string]string{"file": "123"})
+	symlink(t, dir, "file", "link")
+
+	tests := []struct {
+		blank bool
+		want  string
+	}{
+		{false, "4"},
+		{true, "-"},
+	}
+
+	for _, test := range tests {
+		out := render(t, dir, func(opts *Options) { opts.Long, opts.BlankSymlinkSize = true, test.blank })
+
+		if size := fieldsOf(t, out, "link")[1]; size != test.want {
+			t.Errorf("blank %v: size %q, want %q", test.blank, size, test.want)
+		}
+	}
+}
+
+func TestSizeAlign(t *testing.T) {
+	dir := fixture(t, map[string]string{"small": "1", "large": "1234567"})
+
+	tests := []struct {
+		align string
+		want  *regexp.Regexp
+	}{
+		{"right", regexp.MustCompile(`^-rw-r--r-- {6}1  `)},
+		{"left", regexp.MustCompile(`^-rw-r--r--  1 {6}\S`)},
+	}
+
+	for _, test := range tests {
+		out := render(t, dir, func(opts *Options) { opts.Long, opts.SizeAlign = true, test.align })
+
+		if line := lineOf(t, out, "small"); !test.want.MatchString(line) {
+			t.Errorf("%s: size not aligned in %q", test.align, line)
+		}
+	}
+}
+
+func TestSpacing(t *testing.T) {
+	dir := fixture(t, map[string]string{"file": "1"})
+
+	tests := []struct {
+		spacing int
+		gap     string
+	}{
+		{1, " "},
+		{4, "    "},
+	}
+
+	for _, test := range tests {
+		out := render(t, dir, func(opts *Options) { opts.Long, opts.Fast, opts.Spacing = true, true, test.spacing })
+		line := lineOf(t, out, "file")
+
+		if !strings.HasPrefix(line, "-rw-r--r--"+test.gap+"    1"+test.gap) || !strings.HasSuffix(line, test.gap+"file") {
+			t.Errorf("spacing %d: %q", test.spacing, line)
+		}
+	}
+}
+
+func TestLinkDetail(t *testing.T) {
+	dir := fixture(t, map[string]string{"target": strings.Repeat("x", 4096)})
+	symlink(t, dir, "target", "link")
+
+	out := render(t, dir, func(opts *Options) { opts.LinkDetail, opts.Human, opts.ASCIIArrow = true, true, true })
+
+	if want := "link -> " + filepath.Join(dir, "target") + " (4Ki)"; !strings.HasSuffix(lineOf(t, out, "link"), want) {
+		t.Errorf("%q does not end in %q", lineOf(t, out, "link"), want)
+	}
+
+	if size := fieldsOf(t, out, "link")[1]; size == "4Ki" {
+		t.Errorf("size of the link shows the size of its target")
+	}
+}
+
+func TestTypeColumn(t *testing.T) {
+	dir := fixture(t, map[string]string{"dir/": "", "file": ""})
+	symlink(t, dir, "file", "link")
+
+	mkfifo(t, filepath.Join(dir, "pipe"))
+
+	out := render(t, dir, func(opts *Options) { opts.TypeColumn = true })
+
+	for name, kind := range map[string]string{"dir": "dir", "file": "file", "link": "symlink", "pipe": "fifo"} {
+		if fields := fieldsOf(t, out, name); fields[1] != kind {
+			t.Errorf("%s has type %q, want %q", name, fields[1], kind)
+		}
+	}
+}
+
+func TestCollapseOwnerGroup(t *testing.T) {
+	dir := fixture(t, map[string]string{"file": ""})
+	username, groupname, err := fileOwner(lstat(t, filepath.Join(dir, "file")))
+
+	if err != nil || username != groupname {
+		t.Skip("the user and group of the file do not have the same name")
+	}
+
+	tests := []struct {
+		collapse bool
+		want     string
+	}{
+		{false, username + " " + groupname + "  "},
+		{true, " " + username + "  "},
+	}
+
+	for _, test := range tests {
+		out := render(t, dir, func(opts *Options) { opts.Long, opts.CollapseOwnerGroup = true, test.collapse })
+
+		if line := lineOf(t, out, "file"); !strings.Contains(line, test.want) {
+			t.Errorf("collapse %v: %q not in %q", test.collapse, test.want, line)
+		}
+	}
+}
+
+// Returns the details of the file without following a symlink.
+func lstat(t testing.TB, path string) os.FileInfo {
+	t.Helper()
+
+	info, err := os.Lstat(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return info
+}
+
+func TestOwnerSep(t *testing.T) {
+	dir := fixture(t, map[string]string{"file": ""})
+	username, groupname, _ := fileOwner(lstat(t, filepath.Join(dir, "file")))
+
+	for _, sep := range []string{" ", ":", " / "} {
+		out := render(t, dir, func(opts *Options) { opts.Long, opts.OwnerSep = true, sep })
+
+		if want := username + sep + groupname; !strings.Contains(lineOf(t, out, "file"), want) {
+			t.Errorf("%q not in %q", want, lineOf(t, out, "file"))
+		}
+	}
+}
+
+func TestASCIIArrow(t *testing.T) {
+	dir := fixture(t, map[string]string{"file": ""})
+	symlink(t, dir, "file", "link")
+	t.Setenv("LC_ALL", "en_US.UTF-8")
+
+	tests := []struct {
+		ascii bool
+		want  string
+	}{
+		{false, " → "},
+		{true, " -> "},
+	}
+
+	for _, test := range tests {
+		out := render(t, dir, func(opts *Options) { opts.Long, opts.ASCIIArrow = true, test.ascii })
+
+		if !strings.Contains(lineOf(t, out, "link"), "link"+test.want) {
+			t.Errorf("ascii %v: no %q in %q", test.ascii, test.want, lineOf(t, out, "link"))
+		}
+	}
+}
+
+func TestFitWidth(t *testing.T) {
+	dir := fixture(t, map[string]string{"a-rather-long-file-name": "1"})
+	wide := lineOf(t, render(t, dir, func(opts *Options) { opts.Long = true }), "a-rather-long-file-name")
+	username, _, _ := fileOwner(lstat(t, filepath.Join(dir, "a-rather-long-file-name")))
+
+	tests := []struct {
+		width int
+		owner bool
+		date  bool
+	}{
+		{len(wide), true, true},
+		{len(wide) - 1, false, true},
+		{40, false, false},
+	}
+
+	for _, test := range tests {
+		out := render(t, dir, func(opts *Options) { opts.FitWidth, opts.Width = true, test.width })
+		line := lineOf(t, out, "a-rather-long-file-name")
+
+		if strings.Contains(line, username) != test.owner || strings.Contains(line, "2020") != test.date {
+			t.Errorf("width %d: %q", test.width, line)
+		}
+
+		if !strings.HasSuffix(line, "a-rather-long-file-name") {
+			t.Errorf("width %d: name dropped from %q", test.width, line)
+		}
+	}
+}
+
+func TestMatchStats(t *testing.T) {
+	entries := map[string]string{}
+
+	for i := 0; i < 10; i++ {
+		entries["file"+strconv.Itoa(i)+".txt"] = ""
+	}
+
+	dir := fixture(t, entries)
+	out := render(t, dir, func(opts *Options) { opts.MatchStats, opts.Regexp = true, "[123]" })
+
+	if got := lines(out)[len(lines(out))-1]; got != "showing 3 of 10" {
+		t.Errorf("got %q, want showing 3 of 10", got)
+	}
+}
+
+func TestDU(t *testing.T) {
+	dir := fixture(t, map[string]string{"sub/a": "1234", "sub/deeper/b": "123456", "file": "12"})
+
+	out := render(t, dir, func(opts *Options) { opts.DU = true })
+
+	if size := fieldsOf(t, out, "sub")[1]; size != "10" {
+		t.Errorf("size of sub %q, want 10", size)
+	}
+
+	if size := fieldsOf(t, out, "file")[1]; size != "2" {
+		t.Errorf("size of file %q, want 2", size)
+	}
+}
+
+func BenchmarkDU(b *testing.B) {
+	entries := map[string]string{}
+
+	for i := 0; i < 50; i++ {
+		for j := 0; j < 20; j++ {
+			entries["dir"+strconv.Itoa(i)+"/file"+strconv.Itoa(j)] = "1234"
+		}
+	}
+
+	dir := fixture(b, entries)
+	files, err := readDir(dir)
+
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	opts := DefaultOptions()
+	opts.DU = true
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		buildRows(files, dir, opts)
+	}
+}
+
+func TestHeader(t *testing.T) {
+	dir := fixture(t, map[string]string{"file": strings.Repeat("x", 1234567)})
+
+	out := lines(render(t, dir, func(opts *Options) { opts.Header, opts.Fast = true, true }))
+
+	if len(out) != 2 {
+		t.Fatalf("got %q, want a header and a row", out)
+	}
+
+	for _, header := range []string{"Size", "Name"} {
+		column := strings.Index(out[0], header)
+
+		if header == "Size" {
+			// The sizes are aligned to the right, like their header
+			column += len(header) - len("1234567")
+		}
+
+		if column < 0 || !strings.HasPrefix(out[1][column:], map[string]string{"Size": "1234567", "Name": "file"}[header]) {
+			t.Errorf("%s does not line up in %q", header, out)
+		}
+	}
+}
+
+func TestColumnWidths(t *testing.T) {
+	dir := fixture(t, map[string]string{"small": "1", "large": strings.Repeat("x", 1234567)})
+
+	out := render(t, dir, func(opts *Options) { opts.Long = true })
+
+	if strings.Index(lineOf(t, out, "small"), "small") != strings.Index(lineOf(t, out, "large"), "large") {
+		t.Errorf("names do not line up:\n%s", out)
+	}
+}
+
+func TestRealpath(t *testing.T) {
+	dir := fixture(t, map[string]string{"real/file": ""})
+	symlink(t, dir, filepath.Join("real", "file"), "link")
+
+	out := render(t, dir, func(opts *Options) { opts.Realpath = true })
+	resolved, err := filepath.EvalSymlinks(filepath.Join(dir, "real", "file"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "(" + resolved + ")"; !strings.HasSuffix(lineOf(t, out, "link"), want) {
+		t.Errorf("%q does not end in %q", lineOf(t, out, "link"), want)
+	}
+}
+
+func TestOctal(t *testing.T) {
+	tests := []struct {
+		mode os.FileMode
+		want string
+	}{
+		{0644, "0644"},
+		{0755 | os.ModeSetuid, "4755"},
+		{0775 | os.ModeSetgid, "2775"},
+		{0777 | os.ModeSticky, "1777"},
+	}
+
+	for _, test := range tests {
+		if got := octalMode(test.mode); got != test.want {
+			t.Errorf("octalMode(%v) = %q, want %q", test.mode, got, test.want)
+		}
+	}
+
+	dir := fixture(t, map[string]string{"file": ""})
+	out := render(t, dir, func(opts *Options) { opts.Octal = true })
+
+	if fields := fieldsOf(t, out, "file"); fields[0] != "-rw-r--r--" || fields[1] != "0644" {
+		t.Errorf("got %q, want the symbolic and octal permissions", fields[:2])
+	}
+}
+
+type panicWriter struct{ bytes.Buffer }
+
+// Fails in the middle of a listing, after the first coloured cell.
+func (w *panicWriter) Write(p []byte) (int, error) {
+	if w.Len() > 0 && string(p) != colorReset {
+		panic("write failed")
+	}
+
+	return w.Buffer.Write(p)
+}
+
+func TestColorResetOnPanic(t *testing.T) {
+	dir := fixture(t, map[string]string{"file": ""})
+	out := &panicWriter{}
+	opts := DefaultOptions()
+	opts.Long, opts.Color = true, "always"
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("no panic")
+		}
+
+		if !strings.HasSuffix(out.String(), colorReset) {
+			t.Errorf("no reset after %q", out.String())
+		}
+	}()
+
+	Render(out, dir, opts)
+}
+
+func TestSuggest(t *testing.T) {
+	entries := map[string]string{"shown": ""}
+
+	for i := 0; i < 5; i++ {
+		entries[".hidden"+strconv.Itoa(i)] = ""
+	}
+
+	dir := fixture(t, entries)
+
+	tests := []struct {
+		name string
+		set  func(opts *Options)
+		want string
+	}{
+		{"hint", func(opts *Options) {}, "gut: 5 hidden entries — use -a to show them\n"},
+		{"quiet", func(opts *Options) { opts.Quiet = true }, ""},
+	}
+
+	for _, test := range tests {
+		var log bytes.Buffer
+		opts := DefaultOptions()
+		opts.Suggest = true
+		test.set(&opts)
+
+		if err := (&Lister{Output: ioutil.Discard, Log: &log}).Render(dir, opts); err != nil {
+			t.Fatal(err)
+		}
+
+		if log.String() != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, log.String(), test.want)
+		}
+	}
+}
+
+func TestWarnEntries(t *testing.T) {
+	dir := fixture(t, map[string]string{"a": "", "b": "", "c": ""})
+
+	tests := []struct {
+		warn  int
+		limit int
+		want  string
+	}{
+		{2, 0, "gut: 3 entries; use --limit to truncate\n"},
+		{3, 0, ""},
+		{2, 1, ""},
+	}
+
+	for _, test := range tests {
+		var log bytes.Buffer
+		opts := DefaultOptions()
+		opts.WarnEntries, opts.Limit = test.warn, test.limit
+
+		if err := (&Lister{Output: ioutil.Discard, Log: &log}).Render(dir, opts); err != nil {
+			t.Fatal(err)
+		}
+
+		if log.String() != test.want {
+			t.Errorf("warn %d, limit %d: got %q, want %q", test.warn, test.limit, log.String(), test.want)
+		}
+	}
+}
+
+func TestStats(t *testing.T) {
+	dir := fixture(t, map[string]string{"file": ""})
+	var log bytes.Buffer
+	opts := DefaultOptions()
+	opts.Long, opts.Stats = true, true
+
+	if err := (&Lister{Output: ioutil.Discard, Log: &log}).Render(dir, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	var stages []string
+
+	for _, line := range lines(log.String()) {
+		fields := strings.Fields(line)
+
+		if len(fields) != 4 || fields[0] != "gut:" || fields[1] != "stats:" {
+			t.Fatalf("unexpected line %q", line)
+		}
+
+		if _, err := time.ParseDuration(fields[3]); err != nil {
+			t.Errorf("duration of %s: %v", fields[2], err)
+		}
+
+		stages = append(stages, fields[2])
+	}
+
+	if want := "read sort lookup render"; strings.Join(stages, " ") != want {
+		t.Errorf("got stages %q, want %q", stages, want)
+	}
+}
+
+func TestHardlinks(t *testing.T) {
+	dir := fixture(t, map[string]string{"original": "x", "other": "y"})
+
+	if err := os.Link(filepath.Join(dir, "original"), filepath.Join(dir, "copy")); err != nil {
+		t.Fatal(err)
+	}
+
+	out := render(t, dir, func(opts *Options) { opts.GroupHardlinks = true })
+
+	for name, want := range map[string]string{"copy": "copy [hardlink group 1]", "original": "original [hardlink group 1]", "other": " other"} {
+		if line := lineOf(t, out, name); !strings.HasSuffix(line, want) {
+			t.Errorf("%q does not end in %q", line, want)
+		}
+	}
+}
+
+func TestReadDirTimeout(t *testing.T) {
+	dir := fixture(t, map[string]string{"file": ""})
+	read := dirReader
+
+	defer func() { dirReader = read }()
+
+	dirReader = func(path string) ([]os.FileInfo, error) {
+		time.Sleep(200 * time.Millisecond)
+		return read(path)
+	}
+
+	start := time.Now()
+
+	if _, err := readDirWithTimeout(dir, 10*time.Millisecond); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("got %v, want a timeout", err)
+	}
+
+	if time.Since(start) > 150*time.Millisecond {
+		t.Errorf("waited %v for the slow read", time.Since(start))
+	}
+
+	if files, err := readDirWithTimeout(dir, 5*time.Second); err != nil || len(files) != 1 {
+		t.Errorf("got %v, %v, want the file", files, err)
+	}
+}
+
+func TestPathKinds(t *testing.T) {
+	dir := fixture(t, map[string]string{"a.go": "", "b.go": "", "c.txt": "", "sub/": ""})
+
+	tests := []struct {
+		name string
+		path string
+		want []string
+	}{
+		{"directory", dir, []string{"sub", "a.go", "b.go", "c.txt"}},
+		{"file", filepath.Join(dir, "c.txt"), []string{filepath.Join(dir, "c.txt")}},
+		{"glob", filepath.Join(dir, "*.go"), []string{filepath.Join(dir, "a.go"), filepath.Join(dir, "b.go")}},
+	}
+
+	for _, test := range tests {
+		out := render(t, test.path, nil)
+
+		if strings.Join(lines(out), "\n") != strings.Join(test.want, "\n") {
+			t.Errorf("%s: got %q, want %q", test.name, lines(out), test.want)
+		}
+	}
+
+	var out bytes.Buffer
+
+	if err := Render(&out, filepath.Join(dir, "*.md"), DefaultOptions()); err == nil {
+		t.Errorf("no error for a glob without matches")
+	}
+}
+
+func TestGlobCharactersInName(t *testing.T) {
+	dir := fixture(t, map[string]string{"[draft]/": "", "[draft]/notes": ""})
+
+	if out := render(t, filepath.Join(dir, "[draft]"), nil); out != "notes\n" {
+		t.Errorf("directory with brackets in its name taken for a pattern: %q", out)
+	}
+}
@@ -0,0 +1,537 @@
+package list
+
+import (
+	"errors"
+	"flag"
+	"reflect"
+	"time"
+
+	"github.com/urfave/cli"
+)
+
+// Options holds the settings that control what is listed and how. Every field is set by
+// the flag in its tag.
+type Options struct {
+	Regexp                string        `flag:"regexp"`
+	Exclude               string        `flag:"exclude"`
+	IgnoreCase            bool          `flag:"ignore-case"`
+	All                   bool          `flag:"all"`
+	Timeout               time.Duration `flag:"timeout"`
+	Peek                  bool          `flag:"peek"`
+	OnlyRegular           bool          `flag:"only-regular"`
+	FilesOnly             bool          `flag:"files-only"`
+	NoEmpty               bool          `flag:"no-empty"`
+	EmptyOnly             bool          `flag:"empty-only"`
+	Extensions            string        `flag:"ext"`
+	ExtCaseSensitive      bool          `flag:"ext-case-sensitive"`
+	NameLongerThan        int           `flag:"name-longer-than"`
+	NameShorterThan       int           `flag:"name-shorter-than"`
+	Today                 bool          `flag:"today"`
+	SinceBoot             bool          `flag:"since-boot"`
+	Sort                  string        `flag:"sort"`
+	Reverse               bool          `flag:"reverse"`
+	GroupDirectoriesFirst bool          `flag:"group-directories-first"`
+	GroupByDay            bool          `flag:"group-by-day"`
+	GroupByExtension      bool          `flag:"group-by-extension"`
+	GroupHardlinks        bool          `flag:"group-hardlinks"`
+	Limit                 int           `flag:"limit"`
+	WarnEntries           int           `flag:"warn-entries"`
+	Flat                  bool          `flag:"flat"`
+	TrimPrefix            bool          `flag:"trim-prefix"`
+	Tree                  bool          `flag:"tree"`
+	Collapse              bool          `flag:"collapse"`
+	Recursive             bool          `flag:"recursive"`
+	Depth                 int           `flag:"depth"`
+	Long                  bool          `flag:"long"`
+	RecurseIntoHidden     bool          `flag:"recurse-into-hidden"`
+	PruneOlderThan        time.Duration `flag:"prune-older-than"`
+	Human                 bool          `flag:"human"`
+	SmartUnits            bool          `flag:"smart-units"`
+	SI                    bool          `flag:"si"`
+	LongUnits             bool          `flag:"long-units"`
+	DU                    bool          `flag:"du"`
+	SizeAlign             string        `flag:"size-align"`
+	DirMtimeInSize        bool          `flag:"dir-mtime-in-size"`
+	BlankSymlinkSize      bool          `flag:"blank-symlink-size"`
+	TimeStyle             string        `flag:"time-style"`
+	RelativeWithin        time.Duration `flag:"relative-within"`
+	Octal                 bool          `flag:"octal"`
+	Nanoseconds           bool          `flag:"nanoseconds"`
+	Color                 string        `flag:"color"`
+	ACL                   bool          `flag:"acl"`
+	Attrs                 bool          `flag:"attrs"`
+	DimHidden             bool          `flag:"dim-hidden"`
+	Fast                  bool          `flag:"fast"`
+	ASCIIArrow            bool          `flag:"ascii-arrow"`
+	LinkDetail            bool          `flag:"link-detail"`
+	Classify              bool          `flag:"classify"`
+	Realpath              bool          `flag:"realpath"`
+	OwnerSep              string        `flag:"owner-sep"`
+	CollapseOwnerGroup    bool          `flag:"collapse-owner-group"`
+	QuoteRc               bool          `flag:"quote-rc"`
+	PadNames              bool          `flag:"pad-names"`
+	Banner                bool          `flag:"banner"`
+	Header                bool          `flag:"header"`
+	TypeColumn            bool          `flag:"type-column"`
+	Git                   bool          `flag:"git"`
+	GitBranch             bool          `flag:"git-branch"`
+	Spacing               int           `flag:"spacing"`
+	Border                bool          `flag:"border"`
+	GridThreshold         int           `flag:"grid-threshold"`
+	AutoColumns           bool          `flag:"auto-columns"`
+	FitWidth              bool          `flag:"fit-width"`
+	CountRecursive        bool          `flag:"count-recursive"`
+	Total                 bool          `flag:"total"`
+	FilesTotal            bool          `flag:"files-total"`
+	RecursiveTotal        bool          `flag:"recursive-total"`
+	SymlinksAsFiles       bool          `flag:"symlinks-as-files"`
+	Markdown              bool          `flag:"markdown"`
+	HTML                  bool          `flag:"html"`
+	JSON                  bool          `flag:"json"`
+	DescribeColumns       bool          `flag:"describe-columns"`
+	SummaryJSON           bool          `flag:"summary-json"`
+	Stats                 bool          `flag:"stats"`
+	Audit                 bool          `flag:"audit"`
+	MatchStats            bool          `flag:"match-stats"`
+	Suggest               bool          `flag:"suggest"`
+	Strict                bool          `flag:"strict"`
+	Quiet                 bool          `flag:"quiet"`
+
+	// The width to lay out the grid and fit the columns with --fit-width in, 80 columns
+	// for the grid when it is 0.
+	Width int
+
+	// The long names of the flags given on the command line. The flags in the .gutconfig
+	// of a directory go over the other options, but under these.
+	commandLine []string
+}
+
+// Flags are the flags setting the options, for the command line and, the ones only
+// changing how the entries are shown, the .gutconfig of a directory.
+var Flags = []cli.Flag{
+	cli.BoolFlag{
+		Name:  "all, a",
+		Usage: "Show the entries starting with a dot, including . and ..",
+	},
+	cli.StringFlag{
+		Name:  "regexp, x",
+		Value: "",
+		Usage: "Regular expression string to search for files and directories.",
+	},
+	cli.DurationFlag{
+		Name:  "timeout",
+		Usage: "Give up reading the directory after this long, like 5s, instead of hanging.",
+	},
+	cli.StringFlag{
+		Name:  "exclude, X",
+		Usage: "Leave out the entries whose name matches the regular expression.",
+	},
+	cli.BoolFlag{
+		Name:  "ignore-case, i",
+		Usage: "Match --regexp and --exclude case insensitively.",
+	},
+	cli.BoolFlag{
+		Name:  "peek",
+		Usage: "List the files inside a zip or tar archive instead of a directory.",
+	},
+	cli.BoolFlag{
+		Name:  "only-regular",
+		Usage: "Only list regular files, no directories, symlinks or special files.",
+	},
+	cli.BoolFlag{
+		Name:  "files-only",
+		Usage: "Leave out the directories.",
+	},
+	cli.BoolFlag{
+		Name:  "no-empty",
+		Usage: "Leave out the empty files, directories are kept.",
+	},
+	cli.BoolFlag{
+		Name:  "empty-only",
+		Usage: "Only show the empty files, along with the directories.",
+	},
+	cli.StringFlag{
+		Name:  "ext",
+		Usage: "Only list files with one of these comma separated extensions, like go,md.",
+	},
+	cli.BoolFlag{
+		Name:  "ext-case-sensitive",
+		Usage: "Match the extensions given to --ext case sensitively.",
+	},
+	cli.IntFlag{
+		Name:  "name-longer-than",
+		Usage: "Only list the entries with a name of more than this many characters.",
+	},
+	cli.IntFlag{
+		Name:  "name-shorter-than",
+		Usage: "Only list the entries with a name of fewer than this many characters.",
+	},
+	cli.BoolFlag{
+		Name:  "today",
+		Usage: "Only list the entries modified since midnight.",
+	},
+	cli.BoolFlag{
+		Name:  "since-boot",
+		Usage: "Only list the entries modified since the system was booted (Linux).",
+	},
+	cli.StringFlag{
+		Name:  "sort",
+		Usage: "Order of the entries: name, size, time, none or link-target, which sorts symlinks by where they point to.",
+	},
+	cli.BoolFlag{
+		Name:  "reverse, r",
+		Usage: "Reverse the order of the entries.",
+	},
+	cli.BoolFlag{
+		Name:  "group-directories-first",
+		Usage: "List the directories before the other files in any sort order.",
+	},
+	cli.BoolFlag{
+		Name:  "group-by-extension",
+		Usage: "Sort the files by extension, with a heading above every extension.",
+	},
+	cli.BoolFlag{
+		Name:  "group-hardlinks",
+		Usage: "Mark the files that are hard links to the same file with the number of their group.",
+	},
+	cli.BoolFlag{
+		Name:  "group-by-day",
+		Usage: "Sort by time and print the date above the entries of each day.",
+	},
+	cli.IntFlag{
+		Name:  "limit",
+		Usage: "List at most this many entries, 0 for no limit.",
+	},
+	cli.IntFlag{
+		Name:  "warn-entries",
+		Value: 10000,
+		Usage: "Hint at --limit when a listing has more entries than this, 0 to never hint.",
+	},
+	cli.BoolFlag{
+		Name:  "flat",
+		Usage: "List every entry below the directory as a flat list of paths.",
+	},
+	cli.BoolFlag{
+		Name:  "trim-prefix",
+		Usage: "Print the paths in --flat relative to the listed directory.",
+	},
+	cli.BoolFlag{
+		Name:  "tree, t",
+		Usage: "Show the directory and everything below it as a tree.",
+	},
+	cli.BoolFlag{
+		Name:  "collapse",
+		Usage: "Merge directories that only hold a single directory into one line in --tree.",
+	},
+	cli.DurationFlag{
+		Name:  "prune-older-than",
+		Usage: "Leave out directories not modified within this time, like 720h, in recursive listings.",
+	},
+	cli.BoolFlag{
+		Name:  "recurse-into-hidden",
+		Usage: "Also descend into hidden directories in recursive listings.",
+	},
+	cli.BoolFlag{
+		Name:  "recursive, R",
+		Usage: "List the directories below the directory too, each under its own header.",
+	},
+	cli.IntFlag{
+		Name:  "depth",
+		Usage: "Maximum number of directory levels to descend into, 0 for no limit.",
+	},
+	cli.BoolFlag{
+		Name:  "long, l",
+		Usage: "Show the permissions, size, owner and modification time of every entry, implied by the flags adding to them.",
+	},
+	cli.BoolFlag{
+		Name:  "human, h",
+		Usage: "Show sizes in human readable binary units, like 4Ki.",
+	},
+	cli.BoolFlag{
+		Name:  "si",
+		Usage: "Show human readable sizes in powers of 1000, like 4k, instead of 1024.",
+	},
+	cli.BoolFlag{
+		Name:  "smart-units",
+		Usage: "Show human readable sizes with a decimal only below 10 of a unit, like 1.5Gi but 512Ki.",
+	},
+	cli.BoolFlag{
+		Name:  "long-units",
+		Usage: "Show human readable sizes with the units written out, like 1.5 gibibytes.",
+	},
+	cli.StringFlag{
+		Name:  "size-align",
+		Value: "right",
+		Usage: "Align the sizes to the left or right of their column.",
+	},
+	cli.BoolFlag{
+		Name:  "du",
+		Usage: "Show the size of everything below a directory as its size.",
+	},
+	cli.BoolFlag{
+		Name:  "dir-mtime-in-size",
+		Usage: "Show how long ago directories were modified in the size column.",
+	},
+	cli.BoolFlag{
+		Name:  "blank-symlink-size",
+		Usage: "Show a dash instead of the size of symlinks.",
+	},
+	cli.BoolFlag{
+		Name:  "nanoseconds",
+		Usage: "Show the modification time with seconds and nanoseconds.",
+	},
+	cli.StringFlag{
+		Name:  "color",
+		Value: "auto",
+		Usage: "When to colour the output: auto (only on a terminal and without $NO_COLOR), always or never.",
+	},
+	cli.StringFlag{
+		Name:  "time-style",
+		Value: "default",
+		Usage: "How to show the modification time: default, both (relative and absolute), iso, full (RFC 3339) or relative.",
+	},
+	cli.DurationFlag{
+		Name:  "relative-within",
+		Usage: "Show the modification time as an age, like 2 hours ago, for files modified within this time, like 24h.",
+	},
+	cli.BoolFlag{
+		Name:  "git",
+		Usage: "Show the git status of every entry before its name, when listing a git work tree.",
+	},
+	cli.BoolFlag{
+		Name:  "type-column",
+		Usage: "Show the kind of each entry in words, like dir or symlink.",
+	},
+	cli.BoolFlag{
+		Name:  "count-recursive",
+		Usage: "Show the number of files below each directory, up to --depth levels deep, the hidden ones too with --all.",
+	},
+	cli.BoolFlag{
+		Name:  "octal, o",
+		Usage: "Show the permissions as an octal number like 4755 next to the symbolic ones.",
+	},
+	cli.BoolFlag{
+		Name:  "acl",
+		Usage: "Mark files that have an access ACL with a + after the permissions.",
+	},
+	cli.BoolFlag{
+		Name:  "attrs",
+		Usage: "Mark and colour files that are immutable or append-only (chattr +i or +a).",
+	},
+	cli.BoolFlag{
+		Name:  "dim-hidden",
+		Usage: "Show the names of hidden entries in a dim colour.",
+	},
+	cli.BoolFlag{
+		Name:  "ascii-arrow",
+		Usage: "Point symlinks to their target with -> instead of →.",
+	},
+	cli.BoolFlag{
+		Name:  "realpath",
+		Usage: "Show the absolute path with all symlinks resolved after every name.",
+	},
+	cli.BoolFlag{
+		Name:  "link-detail",
+		Usage: "Show the size of the file a symlink points to after its target.",
+	},
+	cli.BoolFlag{
+		Name:  "collapse-owner-group",
+		Usage: "Show the user only once when the group has the same name.",
+	},
+	cli.BoolFlag{
+		Name:  "classify, F",
+		Usage: "Put / after directories, @ after symlinks, * after executables, | after pipes and = after sockets.",
+	},
+	cli.BoolFlag{
+		Name:  "quote-rc",
+		Usage: "Quote names that need it for the rc shell.",
+	},
+	cli.BoolFlag{
+		Name:  "fast",
+		Usage: "Show numeric owner ids and symlink targets as stored, skipping any lookups.",
+	},
+	cli.StringFlag{
+		Name:  "owner-sep",
+		Value: " ",
+		Usage: "Text to put between the user and the group, like : for user:group.",
+	},
+	cli.BoolFlag{
+		Name:  "pad-names",
+		Usage: "Pad the names so whatever is shown after them lines up.",
+	},
+	cli.BoolFlag{
+		Name:  "header, H",
+		Usage: "Print the name of every column above the entries.",
+	},
+	cli.BoolFlag{
+		Name:  "banner",
+		Usage: "Print the absolute path of the listed directory above the listing.",
+	},
+	cli.BoolFlag{
+		Name:  "git-branch",
+		Usage: "Print the git branch the listed directory is on above the listing.",
+	},
+	cli.BoolFlag{
+		Name:  "border",
+		Usage: "Draw a frame around the listing and lines between the columns.",
+	},
+	cli.IntFlag{
+		Name:  "grid-threshold",
+		Value: 8,
+		Usage: "Lay the names out in a grid when a short listing has more than this many entries, one per line otherwise.",
+	},
+	cli.IntFlag{
+		Name:  "spacing",
+		Value: len(defaultSpacer),
+		Usage: "Number of spaces between the columns.",
+	},
+	cli.BoolFlag{
+		Name:  "fit-width",
+		Usage: "Leave out the owner, then the date and the other columns until the listing fits the terminal.",
+	},
+	cli.BoolFlag{
+		Name:  "auto-columns",
+		Usage: "Hide the columns that are empty or the same for every entry.",
+	},
+	cli.BoolFlag{
+		Name:  "total",
+		Usage: "Print the number of files and directories listed and their total size.",
+	},
+	cli.BoolFlag{
+		Name:  "files-total",
+		Usage: "Print the combined size and count of the regular files listed.",
+	},
+	cli.BoolFlag{
+		Name:  "markdown",
+		Usage: "Print the listing as a markdown table.",
+	},
+	cli.BoolFlag{
+		Name:  "symlinks-as-files",
+		Usage: "Count symlinks and their size as files in the totals instead of separately.",
+	},
+	cli.BoolFlag{
+		Name:  "recursive-total",
+		Usage: "Print the number of files and directories and their size in the whole tree.",
+	},
+	cli.BoolFlag{
+		Name:  "html",
+		Usage: "Print the listing as an HTML table with the colours as inline styles.",
+	},
+	cli.BoolFlag{
+		Name:  "json",
+		Usage: "Print the entries as a JSON array of objects instead of columns.",
+	},
+	cli.BoolFlag{
+		Name:  "summary-json",
+		Usage: "Only print the counts and sizes of the listing, per extension too, as JSON.",
+	},
+	cli.BoolFlag{
+		Name:  "audit",
+		Usage: "Flag world-writable, setuid and setgid files and files without an existing owner.",
+	},
+	cli.BoolFlag{
+		Name:  "describe-columns",
+		Usage: "Print the columns of the listing with their width and alignment as JSON, without the entries.",
+	},
+	cli.BoolFlag{
+		Name:  "stats",
+		Usage: "Print how long reading, sorting, looking up and rendering took to stderr.",
+	},
+	cli.BoolFlag{
+		Name:  "strict",
+		Usage: "Mark entries whose details could not be read and exit with a non-zero code.",
+	},
+	cli.BoolFlag{
+		Name:  "match-stats",
+		Usage: "Print how many of the entries are shown after filtering.",
+	},
+	cli.BoolFlag{
+		Name:  "suggest",
+		Usage: "Print a hint on what to look at next after the listing, like the hidden entries.",
+	},
+	cli.BoolFlag{
+		Name:  "quiet, q",
+		Usage: "Do not print warnings, they still make gut exit with a non-zero code.",
+	},
+}
+
+// NewOptions returns the options set by the flags in the context. The command line holds
+// the long names of the flags given on it, as opposed to in GUT_DEFAULT_ARGS, which the
+// .gutconfig of a directory can not change.
+func NewOptions(c *cli.Context, commandLine []string) Options {
+	opts := Options{commandLine: commandLine}
+	setOptions(&opts, c, func(name string) bool { return true })
+
+	return opts
+}
+
+// DefaultOptions returns the options the flags default to, to list with when no flags
+// are given.
+func DefaultOptions() Options {
+	set := flag.NewFlagSet("gut", flag.ContinueOnError)
+
+	for _, f := range Flags {
+		f.Apply(set)
+	}
+
+	return NewOptions(cli.NewContext(nil, set, nil), nil)
+}
+
+// Sets the fields of the options from the values of the flags they are tagged with, for
+// the flags that are picked only.
+func setOptions(opts *Options, c *cli.Context, pick func(name string) bool) {
+	fields := reflect.ValueOf(opts).Elem()
+
+	for i := 0; i < fields.NumField(); i++ {
+		name := fields.Type().Field(i).Tag.Get("flag")
+
+		if name == "" || !pick(name) {
+			continue
+		}
+
+		switch field := fields.Field(i); field.Interface().(type) {
+		case bool:
+			field.SetBool(c.Bool(name))
+		case string:
+			field.SetString(c.String(name))
+		case int:
+			field.SetInt(int64(c.Int(name)))
+		case time.Duration:
+			field.SetInt(int64(c.Duration(name)))
+		}
+	}
+}
+
+// CheckOptions returns an error for the first of the options that has a value gut does
+// not know.
+func CheckOptions(opts Options) error {
+	if !isTimeStyle(opts.TimeStyle) {
+		return errors.New("unknown time style: " + opts.TimeStyle)
+	}
+
+	if opts.Color != "always" && opts.Color != "never" && opts.Color != "auto" {
+		return errors.New("unknown color mode: " + opts.Color)
+	}
+
+	if opts.Sort != "" && !isSortOrder(opts.Sort) {
+		return errors.New("unknown sort order: " + opts.Sort)
+	}
+
+	if opts.Spacing < 0 {
+		return errors.New("spacing can not be negative")
+	}
+
+	if opts.SizeAlign != "left" && opts.SizeAlign != "right" {
+		return errors.New("unknown size alignment: " + opts.SizeAlign)
+	}
+
+	if (opts.JSON || opts.SummaryJSON) && (opts.Recursive || opts.Tree || opts.Flat) {
+		return errors.New("--json and --summary-json can not be used with --recursive, --tree or --flat")
+	}
+
+	if opts.NoEmpty && opts.EmptyOnly {
+		return errors.New("--no-empty and --empty-only can not be used together")
+	}
+
+	return nil
+}
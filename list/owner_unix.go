@@ -0,0 +1,50 @@
+//go:build !windows
+
+package list
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"syscall"
+)
+
+// Files have an owner and group on every system but Windows.
+const hasOwners = true
+
+// Returns the ids of the owner and group of the file. Files in an archive carry no ids,
+// in which case ok is false.
+func ownerIDs(file os.FileInfo) (uid string, gid string, ok bool) {
+	stat, ok := file.Sys().(*syscall.Stat_t)
+
+	if !ok {
+		return "", "", false
+	}
+
+	return fmt.Sprint(stat.Uid), fmt.Sprint(stat.Gid), true
+}
+
+// Returns the names of the owner and group of a file, the numbers for an id without a
+// name along with the error of the first lookup that failed. Both are blank for files
+// without ids.
+func fileOwner(file os.FileInfo) (username string, groupname string, err error) {
+	username, groupname, ok := ownerIDs(file)
+
+	if !ok {
+		return "", "", nil
+	}
+
+	if owner, lookupErr := user.LookupId(username); lookupErr == nil {
+		username = owner.Username
+	} else {
+		err = lookupErr
+	}
+
+	if group, lookupErr := user.LookupGroupId(groupname); lookupErr == nil {
+		groupname = group.Name
+	} else if err == nil {
+		err = lookupErr
+	}
+
+	return username, groupname, err
+}
@@ -0,0 +1,82 @@
+//go:build !windows
+
+package list
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileOwner(t *testing.T) {
+	dir := fixture(t, map[string]string{"file": ""})
+	current, err := user.Current()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	group, err := user.LookupGroupId(current.Gid)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	username, groupname, err := fileOwner(lstat(t, filepath.Join(dir, "file")))
+
+	if err != nil || username != current.Username || groupname != group.Name {
+		t.Errorf("got %q, %q, %v, want %q, %q", username, groupname, err, current.Username, group.Name)
+	}
+}
+
+func TestFileOwnerWithoutNames(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("only root can give a file away")
+	}
+
+	dir := fixture(t, map[string]string{"file": ""})
+	path := filepath.Join(dir, "file")
+
+	if err := os.Lchown(path, 4242, 4243); err != nil {
+		t.Fatal(err)
+	}
+
+	username, groupname, err := fileOwner(lstat(t, path))
+
+	if err == nil || username != "4242" || groupname != "4243" {
+		t.Errorf("got %q, %q, %v, want the ids and an error", username, groupname, err)
+	}
+
+	out := render(t, dir, func(opts *Options) { opts.Long = true })
+
+	if fields := fieldsOf(t, out, "file"); fields[2] != "4242" || fields[3] != "4243" {
+		t.Errorf("got %q, want the ids", lineOf(t, out, "file"))
+	}
+}
+
+func TestFileOwnerWithoutIDs(t *testing.T) {
+	dir := t.TempDir()
+	writeTarGz(t, dir)
+
+	if username, groupname, err := fileOwner(nilSys{lstat(t, dir)}); username != "" || groupname != "" || err != nil {
+		t.Errorf("got %q, %q, %v, want blanks", username, groupname, err)
+	}
+
+	// The files in an archive have no Stat_t to take the owner from
+	out := render(t, filepath.Join(dir, "test.tar.gz"), func(opts *Options) { opts.Long, opts.Peek = true, true })
+
+	if line := lineOf(t, out, "medium"); strings.Contains(line, "root") {
+		t.Errorf("got an owner for a file in an archive: %q", line)
+	}
+}
+
+// A nilSys is a file without any system specific information.
+type nilSys struct {
+	os.FileInfo
+}
+
+func (nilSys) Sys() interface{} {
+	return nil
+}
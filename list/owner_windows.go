@@ -0,0 +1,16 @@
+//go:build windows
+
+package list
+
+import "os"
+
+// Windows has no owner and group in the file details, so the column is left out.
+const hasOwners = false
+
+func ownerIDs(file os.FileInfo) (uid string, gid string, ok bool) {
+	return "", "", false
+}
+
+func fileOwner(file os.FileInfo) (username string, groupname string, err error) {
+	return "", "", nil
+}
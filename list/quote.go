@@ -0,0 +1,16 @@
+package list
+
+import "strings"
+
+// The characters that make a name need quoting in rc, the Plan 9 shell.
+const rcSpecial = " \t\n'#;&|^$=`{}()<>*?[]~\\\""
+
+// Returns the name as it is printed, quoted for the shell chosen with the options.
+func escapeName(name string, opts Options) string {
+	if opts.QuoteRc && strings.ContainsAny(name, rcSpecial) {
+		// rc has no escapes, a quote inside quotes is written twice
+		return "'" + strings.Replace(name, "'", "''", -1) + "'"
+	}
+
+	return name
+}
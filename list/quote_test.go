@@ -0,0 +1,34 @@
+package list
+
+import "testing"
+
+func TestEscapeName(t *testing.T) {
+	tests := []struct {
+		name    string
+		quoteRc bool
+		want    string
+	}{
+		{"plain", true, "plain"},
+		{"with space", true, "'with space'"},
+		{"it's", true, "'it''s'"},
+		{"a$b", true, "'a$b'"},
+		{"sum=1", true, "'sum=1'"},
+		{"with space", false, "with space"},
+	}
+
+	for _, test := range tests {
+		if got := escapeName(test.name, Options{QuoteRc: test.quoteRc}); got != test.want {
+			t.Errorf("escapeName(%q) with quote rc %v = %q, want %q", test.name, test.quoteRc, got, test.want)
+		}
+	}
+}
+
+func TestQuoteRcListing(t *testing.T) {
+	dir := fixture(t, map[string]string{"it's": "", "plain": ""})
+
+	out := render(t, dir, func(opts *Options) { opts.Long, opts.QuoteRc = true, true })
+
+	if fields := fieldsOf(t, out, "'it''s'"); fields[len(fields)-1] != "'it''s'" {
+		t.Errorf("got %q, want the name quoted", fields)
+	}
+}
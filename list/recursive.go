@@ -0,0 +1,68 @@
+package list
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Lists the directory and then every directory below it depth first, each under a
+// header with its path relative to the listed directory, like ls -R does. Symlinks to
+// directories are listed but not followed, so a link back up the tree can not loop.
+func (l *Lister) outputRecursive(path string, filters []filter, opts Options) error {
+	return l.listRecursive(path, ".", filters, opts.Depth, opts)
+}
+
+// Lists one directory and then the ones below it. The .gutconfig of a directory only
+// changes how that directory is listed, the walk goes on with the options it was given.
+func (l *Lister) listRecursive(path string, relative string, filters []filter, depth int, opts Options) error {
+	// The header comes first like in ls -R, so a directory that can not be read is still
+	// named in the listing
+	fmt.Fprintln(l.Output, relative+":")
+
+	files, err := readDir(path)
+
+	if err != nil {
+		return err
+	}
+
+	dirOpts, dirFilters := l.dirOptions(path, opts, filters)
+	shown := removePruned(removeHidden(files, dirOpts), dirOpts)
+	sortFiles(shown, path, dirOpts)
+
+	listed := filterFiles(shown, dirFilters)
+	l.listed += len(listed)
+
+	problems, _ := l.outputFiles(listed, path, dirOpts)
+
+	for _, problem := range problems {
+		l.warn(problem)
+	}
+
+	if dirOpts.Total {
+		printTotal(l.Output, listed, path, dirOpts)
+	}
+
+	files = removePruned(removeHidden(files, opts), opts)
+	sortFiles(files, path, opts)
+
+	if depth == 1 {
+		return nil
+	}
+
+	for _, file := range files {
+		if file.Mode()&os.ModeSymlink != 0 || !descendInto(file, opts) {
+			continue
+		}
+
+		fmt.Fprintln(l.Output)
+
+		err := l.listRecursive(filepath.Join(path, file.Name()), filepath.Join(relative, file.Name()), filters, depth-1, opts)
+
+		if err != nil {
+			l.warn(err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,169 @@
+package list
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecursive(t *testing.T) {
+	dir := fixture(t, map[string]string{
+		"file":          "",
+		"sub/inner":     "",
+		"sub/deeper/x":  "",
+		"old/stale":     "",
+		".hidden/found": "",
+	})
+	symlink(t, dir, "sub", "link")
+	touch(t, filepath.Join(dir, "sub"), time.Now())
+	touch(t, filepath.Join(dir, ".hidden"), time.Now())
+
+	tests := []struct {
+		name string
+		set  func(opts *Options)
+		want []string
+	}{
+		{"headers", func(opts *Options) {}, []string{
+			".:", "old", "sub", "file", "link",
+			"", "old:", "stale",
+			"", "sub:", "deeper", "inner",
+			"", "sub/deeper:", "x",
+		}},
+		{"depth", func(opts *Options) { opts.Depth = 2 }, []string{
+			".:", "old", "sub", "file", "link",
+			"", "old:", "stale",
+			"", "sub:", "deeper", "inner",
+		}},
+		{"all without recursing into hidden", func(opts *Options) { opts.All, opts.Depth = true, 1 }, []string{
+			".:", ".hidden", "old", "sub", "file", "link",
+		}},
+		{"recurse into hidden", func(opts *Options) { opts.All, opts.RecurseIntoHidden, opts.Regexp = true, true, "^[^.]" }, []string{
+			".:", "old", "sub", "file", "link",
+			"", ".hidden:", "found",
+			"", "old:", "stale",
+			"", "sub:", "deeper", "inner",
+			"", "sub/deeper:", "x",
+		}},
+		{"prune older than", func(opts *Options) { opts.PruneOlderThan = 24 * time.Hour }, []string{
+			".:", "sub", "file", "link",
+			"", "sub:", "deeper", "inner",
+			"", "sub/deeper:", "x",
+		}},
+	}
+
+	for _, test := range tests {
+		out := render(t, dir, func(opts *Options) {
+			opts.Recursive = true
+			test.set(opts)
+		})
+
+		var got []string
+
+		for _, line := range lines(out) {
+			if fields := strings.Fields(line); len(fields) == 0 {
+				got = append(got, "")
+			} else {
+				got = append(got, fields...)
+			}
+		}
+
+		if strings.Join(got, " ") != strings.Join(test.want, " ") {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestRecursiveNoAccess(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can look into any directory")
+	}
+
+	dir := fixture(t, map[string]string{"locked/": "", "locked/file": "", "open/file": ""})
+
+	if err := os.Chmod(filepath.Join(dir, "locked"), 0); err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.Chmod(filepath.Join(dir, "locked"), 0755)
+
+	var out bytes.Buffer
+	var problems []error
+	opts := DefaultOptions()
+	opts.Recursive = true
+	lister := &Lister{Output: &out, Warn: func(err error) { problems = append(problems, err) }}
+
+	if err := lister.Render(dir, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := ".:\nlocked\nopen\n\nlocked:\n\nopen:\nfile\n"; out.String() != want || len(problems) != 1 {
+		t.Errorf("got %q and problems %v, want %q and the locked directory", out.String(), problems, want)
+	}
+}
+
+func TestRecursiveTotal(t *testing.T) {
+	dir := fixture(t, map[string]string{"a": "12", "sub/b": "123", "sub/deeper/c": "1234", ".hidden": "12345"})
+	symlink(t, dir, "a", "link")
+
+	tests := []struct {
+		name string
+		set  func(opts *Options)
+		want string
+	}{
+		{"listing", func(opts *Options) {}, "3 files, 2 directories, 1 symlinks, 9 total"},
+		{"recursive", func(opts *Options) { opts.Recursive = true }, "3 files, 2 directories, 1 symlinks, 9 total"},
+		{"tree", func(opts *Options) { opts.Tree = true }, "3 files, 2 directories, 1 symlinks, 9 total"},
+		{"flat", func(opts *Options) { opts.Flat = true }, "3 files, 2 directories, 1 symlinks, 9 total"},
+		{"depth", func(opts *Options) { opts.Depth = 2 }, "2 files, 2 directories, 1 symlinks, 5 total"},
+		{"all", func(opts *Options) { opts.All = true }, "4 files, 2 directories, 1 symlinks, 14 total"},
+		{"filters", func(opts *Options) { opts.Regexp = "^[ab]$" }, "2 files, 0 directories, 5 total"},
+		{"symlinks as files", func(opts *Options) { opts.SymlinksAsFiles = true }, "4 files, 2 directories, " + linkSize(t, dir, "link") + " total"},
+	}
+
+	for _, test := range tests {
+		out := render(t, dir, func(opts *Options) {
+			opts.RecursiveTotal = true
+			test.set(opts)
+		})
+
+		if got := lines(out); got[len(got)-1] != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, got[len(got)-1], test.want)
+		}
+	}
+}
+
+// Returns the size of the files of TestRecursiveTotal when the symlink counts as one.
+func linkSize(t testing.TB, dir string, name string) string {
+	t.Helper()
+
+	return formatSize(9+lstat(t, filepath.Join(dir, name)).Size(), DefaultOptions())
+}
+
+func TestPruneOlderThan(t *testing.T) {
+	dir := fixture(t, map[string]string{"old/stale": "", "new/fresh": "", "file": ""})
+	touch(t, filepath.Join(dir, "new"), time.Now())
+
+	tests := []struct {
+		name string
+		set  func(opts *Options)
+		want string
+	}{
+		{"flat", func(opts *Options) { opts.Flat, opts.TrimPrefix = true, true }, "new new/fresh file"},
+		{"tree", func(opts *Options) { opts.Tree = true }, dir + " ├── new │ └── fresh └── file"},
+		{"not in a plain listing", func(opts *Options) {}, "new old file"},
+	}
+
+	for _, test := range tests {
+		out := render(t, dir, func(opts *Options) {
+			opts.PruneOlderThan = 24 * time.Hour
+			test.set(opts)
+		})
+
+		if got := strings.Join(strings.Fields(out), " "); got != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+// Package list lists directories, archives and files the way gut prints them.
+package list
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
+)
+
+// A Lister lists paths into its output. The problems that do not stop a listing go to
+// Warn, the --stats and hints to Log. Either is dropped when it is nil.
+type Lister struct {
+	Output io.Writer
+	Warn   func(error)
+	Log    io.Writer
+
+	listed int
+}
+
+// Render lists a path into the writer the way gut prints it for the options, reading,
+// filtering, sorting and printing the entries. Start from DefaultOptions, as the options
+// are checked like the flags are. The first problem that did not stop the listing is
+// returned when there was no other error.
+func Render(w io.Writer, path string, opts Options) error {
+	var problem error
+
+	lister := &Lister{Output: w, Warn: func(err error) {
+		if problem == nil {
+			problem = err
+		}
+	}}
+
+	if err := lister.Render(path, opts); err != nil {
+		return err
+	}
+
+	return problem
+}
+
+// Render lists a single path into the output.
+func (l *Lister) Render(path string, opts Options) error {
+	return l.RenderAll([]string{path}, opts)
+}
+
+// RenderAll lists the paths one after the other into the output, each under its name
+// when there are several, like ls does. Like in ls the files among several paths are
+// listed together first, without a name. The problems with one of several paths go to
+// Warn, so the others are still listed.
+func (l *Lister) RenderAll(paths []string, opts Options) error {
+	if err := CheckOptions(opts); err != nil {
+		return err
+	}
+
+	// A bad pattern is reported once instead of for every path
+	filters, err := buildFilters(opts)
+
+	if err != nil {
+		return err
+	}
+
+	defer func(noColor bool) { color.NoColor = noColor }(color.NoColor)
+	color.NoColor = !useColor(l.Output, opts)
+
+	if opts.JSON || opts.SummaryJSON {
+		return l.outputJSON(paths, filters, opts)
+	}
+
+	var files, dirs []string
+
+	for _, path := range paths {
+		if len(paths) > 1 && isFileArg(path, opts) {
+			files = append(files, path)
+		} else {
+			dirs = append(dirs, path)
+		}
+	}
+
+	listedFiles := len(files) > 0 && l.listFiles(files, filters, opts)
+
+	for i, path := range dirs {
+		// Several paths are listed one after the other under their name, or under the
+		// banner which names them already
+		if i > 0 || listedFiles {
+			fmt.Fprintln(l.Output)
+		}
+
+		if len(paths) > 1 && !opts.Banner {
+			fmt.Fprintln(l.Output, path+":")
+		}
+
+		if err := l.listPath(path, filters, opts); err != nil {
+			if len(paths) == 1 {
+				return err
+			}
+
+			l.warn(err)
+		}
+	}
+
+	return nil
+}
+
+// Returns whether the path given on the command line is a file to list along with the other
+// files given, rather than a directory, archive or glob to list the entries of.
+func isFileArg(path string, opts Options) bool {
+	return !opts.Peek && !isGlob(path) && !isDirectory(path)
+}
+
+// Listed returns the number of entries listed so far.
+func (l *Lister) Listed() int {
+	return l.listed
+}
+
+// Reports a problem that does not stop the listing.
+func (l *Lister) warn(err error) {
+	if l.Warn != nil {
+		l.Warn(err)
+	}
+}
+
+// Writes a line of --stats or a hint to the log, unless there is none.
+func (l *Lister) logf(format string, args ...interface{}) {
+	if l.Log != nil {
+		fmt.Fprintf(l.Log, "gut: "+format+"\n", args...)
+	}
+}
+
+// Returns whether the listing is coloured. Colours only end up as noise in a file or
+// another program, and they would make the JSON invalid.
+func useColor(w io.Writer, opts Options) bool {
+	if opts.JSON {
+		return false
+	}
+
+	switch opts.Color {
+	case "always":
+		return true
+	case "auto":
+		file, ok := w.(*os.File)
+
+		return ok && os.Getenv("NO_COLOR") == "" && IsTerminal(file)
+	}
+
+	return false
+}
+
+// IsTerminal returns whether the file is a terminal rather than a regular file, a pipe or
+// another character device like /dev/null.
+func IsTerminal(file *os.File) bool {
+	return isatty.IsTerminal(file.Fd()) || isatty.IsCygwinTerminal(file.Fd())
+}
@@ -0,0 +1,193 @@
+package list
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+func TestRender(t *testing.T) {
+	dir := fixture(t, map[string]string{"file": "", "sub/": ""})
+	var out bytes.Buffer
+
+	if err := Render(&out, dir, DefaultOptions()); err != nil {
+		t.Fatal(err)
+	}
+
+	if out.String() != "sub\nfile\n" {
+		t.Errorf("got %q", out.String())
+	}
+}
+
+func TestRenderChecksOptions(t *testing.T) {
+	dir := fixture(t, map[string]string{"file": ""})
+
+	tests := []struct {
+		name string
+		set  func(opts *Options)
+		want string
+	}{
+		{"zero options", func(opts *Options) { *opts = Options{} }, "unknown time style: "},
+		{"color", func(opts *Options) { opts.Color = "sometimes" }, "unknown color mode: sometimes"},
+		{"sort", func(opts *Options) { opts.Sort = "sideways" }, "unknown sort order: sideways"},
+		{"json recursive", func(opts *Options) { opts.JSON, opts.Recursive = true, true }, "--json and --summary-json can not be used with --recursive, --tree or --flat"},
+		{"no empty and empty only", func(opts *Options) { opts.NoEmpty, opts.EmptyOnly = true, true }, "--no-empty and --empty-only can not be used together"},
+	}
+
+	for _, test := range tests {
+		opts := DefaultOptions()
+		test.set(&opts)
+		var out bytes.Buffer
+
+		if err := Render(&out, dir, opts); err == nil || err.Error() != test.want || out.Len() > 0 {
+			t.Errorf("%s: got %v and %q, want %q and no output", test.name, err, out.String(), test.want)
+		}
+	}
+}
+
+func TestRenderColor(t *testing.T) {
+	dir := fixture(t, map[string]string{"sub/": ""})
+
+	for _, noColor := range []bool{false, true} {
+		color.NoColor = noColor
+
+		tests := []struct {
+			mode string
+			want string
+		}{
+			{"always", colored(colorPermDir, "sub") + "\n"},
+			{"never", "sub\n"},
+			{"auto", "sub\n"},
+		}
+
+		for _, test := range tests {
+			if out := render(t, dir, func(opts *Options) { opts.Color = test.mode }); out != test.want {
+				t.Errorf("%s: got %q, want %q", test.mode, out, test.want)
+			}
+
+			if color.NoColor != noColor {
+				t.Errorf("%s: the colours were left turned %v", test.mode, !color.NoColor)
+			}
+		}
+	}
+}
+
+func TestRenderReturnsTheFirstProblem(t *testing.T) {
+	dir := fixture(t, map[string]string{".gutconfig": "--no-such-flag", "file": ""})
+	var out bytes.Buffer
+
+	if err := Render(&out, dir, DefaultOptions()); err == nil || !strings.Contains(err.Error(), "no-such-flag") {
+		t.Errorf("got %v, want the problem with the .gutconfig", err)
+	}
+
+	if out.String() != "file\n" {
+		t.Errorf("got %q, want the listing all the same", out.String())
+	}
+
+	if err := Render(&out, filepath.Join(dir, "missing"), DefaultOptions()); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("got %v, want the path to not exist", err)
+	}
+}
+
+func TestRenderAll(t *testing.T) {
+	dir := fixture(t, map[string]string{"one/a": "", "two/b": ""})
+	one, two, missing := filepath.Join(dir, "one"), filepath.Join(dir, "two"), filepath.Join(dir, "missing")
+
+	tests := []struct {
+		name     string
+		paths    []string
+		set      func(opts *Options)
+		want     string
+		warnings int
+	}{
+		{"one path", []string{one}, func(opts *Options) {}, "a\n", 0},
+		{"several paths", []string{two, one}, func(opts *Options) {}, two + ":\nb\n\n" + one + ":\na\n", 0},
+		{"banner", []string{one, two}, func(opts *Options) { opts.Banner = true }, one + "\na\n\n" + two + "\nb\n", 0},
+		{"missing path", []string{one, missing, two}, func(opts *Options) {}, one + ":\na\n\n" + two + ":\nb\n", 1},
+		{"files first", []string{one, filepath.Join(two, "b")}, func(opts *Options) {}, filepath.Join(two, "b") + "\n\n" + one + ":\na\n", 0},
+		{"only files", []string{filepath.Join(two, "b"), filepath.Join(one, "a")}, func(opts *Options) {}, filepath.Join(one, "a") + "\n" + filepath.Join(two, "b") + "\n", 0},
+	}
+
+	for _, test := range tests {
+		var out bytes.Buffer
+		var warnings []error
+		lister := &Lister{Output: &out, Warn: func(err error) { warnings = append(warnings, err) }}
+		opts := DefaultOptions()
+		test.set(&opts)
+
+		if err := lister.RenderAll(test.paths, opts); err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+
+		if out.String() != test.want || len(warnings) != test.warnings {
+			t.Errorf("%s: got %q with warnings %v, want %q with %d", test.name, out.String(), warnings, test.want, test.warnings)
+		}
+
+		if lister.Listed() != 2 && len(test.paths) > 1 {
+			t.Errorf("%s: listed %d entries, want 2", test.name, lister.Listed())
+		}
+	}
+}
+
+func TestBanner(t *testing.T) {
+	dir := fixture(t, map[string]string{"file": ""})
+
+	out := render(t, dir, func(opts *Options) { opts.Banner = true })
+
+	if want := dir + "\nfile\n"; out != want {
+		t.Errorf("got %q, want %q", out, want)
+	}
+
+	out = render(t, dir, func(opts *Options) { opts.Banner, opts.JSON = true, true })
+
+	if strings.HasPrefix(out, dir) {
+		t.Errorf("got the banner in the JSON: %q", out)
+	}
+}
+
+func TestUseColor(t *testing.T) {
+	file, err := os.Create(filepath.Join(t.TempDir(), "out"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer file.Close()
+
+	null, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer null.Close()
+
+	tests := []struct {
+		name string
+		w    io.Writer
+		set  func(opts *Options)
+		want bool
+	}{
+		{"always", &bytes.Buffer{}, func(opts *Options) { opts.Color = "always" }, true},
+		{"never", &bytes.Buffer{}, func(opts *Options) { opts.Color = "never" }, false},
+		{"auto into a buffer", &bytes.Buffer{}, func(opts *Options) {}, false},
+		{"auto into a file", file, func(opts *Options) {}, false},
+		{"auto into the null device", null, func(opts *Options) {}, false},
+		{"always with json", &bytes.Buffer{}, func(opts *Options) { opts.Color, opts.JSON = "always", true }, false},
+	}
+
+	for _, test := range tests {
+		opts := DefaultOptions()
+		test.set(&opts)
+
+		if got := useColor(test.w, opts); got != test.want {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
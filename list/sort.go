@@ -0,0 +1,189 @@
+package list
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// byLinkTarget sorts symlinks by the path they resolve to, after all other files which
+// are sorted like byDir. Every target is resolved once, before sorting.
+type byLinkTarget struct {
+	files   []os.FileInfo
+	targets []string
+}
+
+func newByLinkTarget(files []os.FileInfo, path string) byLinkTarget {
+	targets := make([]string, len(files))
+
+	for i, file := range files {
+		if file.Mode()&os.ModeSymlink == 0 {
+			continue
+		}
+
+		target, err := filepath.EvalSymlinks(filepath.Join(path, file.Name()))
+
+		if err != nil {
+			// Broken links sort by where they point to, resolved or not
+			target, _ = os.Readlink(filepath.Join(path, file.Name()))
+		}
+
+		targets[i] = target
+	}
+
+	return byLinkTarget{files, targets}
+}
+
+func (a byLinkTarget) Len() int { return len(a.files) }
+func (a byLinkTarget) Swap(i, j int) {
+	a.files[i], a.files[j] = a.files[j], a.files[i]
+	a.targets[i], a.targets[j] = a.targets[j], a.targets[i]
+}
+func (a byLinkTarget) Less(i, j int) bool {
+	iLink := a.files[i].Mode()&os.ModeSymlink != 0
+	jLink := a.files[j].Mode()&os.ModeSymlink != 0
+
+	if iLink && jLink && a.targets[i] != a.targets[j] {
+		return a.targets[i] < a.targets[j]
+	} else if iLink != jLink {
+		return jLink
+	}
+
+	return byDir(a.files).Less(i, j)
+}
+
+// byExtension sorts the directories first and then the files by extension, files
+// without one before the others, and by name within the same extension.
+type byExtension []os.FileInfo
+
+func (a byExtension) Len() int      { return len(a) }
+func (a byExtension) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a byExtension) Less(i, j int) bool {
+	if a[i].IsDir() != a[j].IsDir() {
+		return a[i].IsDir()
+	}
+
+	if iExt, jExt := filepath.Ext(a[i].Name()), filepath.Ext(a[j].Name()); iExt != jExt {
+		return iExt < jExt
+	}
+
+	return a[i].Name() < a[j].Name()
+}
+
+// byModTime sorts the files from the most recently modified to the least recently
+// modified, by name when modified at the same time.
+type byModTime []os.FileInfo
+
+func (a byModTime) Len() int      { return len(a) }
+func (a byModTime) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a byModTime) Less(i, j int) bool {
+	if !a[i].ModTime().Equal(a[j].ModTime()) {
+		return a[i].ModTime().After(a[j].ModTime())
+	}
+
+	return a[i].Name() < a[j].Name()
+}
+
+// byName sorts the files by name only, directories mixed in with the other files.
+type byName []os.FileInfo
+
+func (a byName) Len() int           { return len(a) }
+func (a byName) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a byName) Less(i, j int) bool { return a[i].Name() < a[j].Name() }
+
+// bySize sorts the files from the largest to the smallest, by name when they are the
+// same size. Directories have no size of their own and count as empty.
+type bySize []os.FileInfo
+
+func (a bySize) Len() int      { return len(a) }
+func (a bySize) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a bySize) Less(i, j int) bool {
+	if fileSize(a[i]) != fileSize(a[j]) {
+		return fileSize(a[i]) > fileSize(a[j])
+	}
+
+	return a[i].Name() < a[j].Name()
+}
+
+func fileSize(file os.FileInfo) int64 {
+	if file.IsDir() {
+		return 0
+	}
+
+	return file.Size()
+}
+
+// inReadOrder leaves the files in the order the file system returned them. It is only
+// of use in a stable sort, like to move the directories to the front.
+type inReadOrder []os.FileInfo
+
+func (a inReadOrder) Len() int           { return len(a) }
+func (a inReadOrder) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a inReadOrder) Less(i, j int) bool { return false }
+
+// dirsFirst puts the directories before the other files, each sorted in the order it
+// wraps.
+type dirsFirst struct {
+	sort.Interface
+	files []os.FileInfo
+}
+
+func (a dirsFirst) Less(i, j int) bool {
+	if a.files[i].IsDir() != a.files[j].IsDir() {
+		return a.files[i].IsDir()
+	}
+
+	return a.Interface.Less(i, j)
+}
+
+// The orders --sort accepts. Without one the files are sorted directories first and
+// then by name, by time when grouped by day or by extension when grouped by extension.
+var sortOrders = []string{"name", "size", "time", "none", "link-target"}
+
+func isSortOrder(order string) bool {
+	for _, known := range sortOrders {
+		if order == known {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Sorts the files of a directory in the order given by --sort, reversed with --reverse.
+// With --group-directories-first the directories come first in either direction.
+func sortFiles(files []os.FileInfo, path string, opts Options) {
+	var order sort.Interface
+
+	switch {
+	case opts.Sort == "link-target":
+		order = newByLinkTarget(files, path)
+	case opts.Sort == "name":
+		order = byName(files)
+	case opts.Sort == "size":
+		order = bySize(files)
+	case opts.Sort == "time" || (opts.Sort == "" && opts.GroupByDay):
+		order = byModTime(files)
+	case opts.Sort == "" && opts.GroupByExtension:
+		order = byExtension(files)
+	case opts.Sort == "none":
+		order = inReadOrder(files)
+	default:
+		order = byDir(files)
+	}
+
+	if opts.Reverse && opts.Sort == "none" {
+		// There is nothing to compare, so the read order is turned around as is
+		for i, j := 0, len(files)-1; i < j; i, j = i+1, j-1 {
+			order.Swap(i, j)
+		}
+	} else if opts.Reverse {
+		order = sort.Reverse(order)
+	}
+
+	if opts.GroupDirectoriesFirst {
+		order = dirsFirst{order, files}
+	}
+
+	sort.Stable(order)
+}
@@ -0,0 +1,151 @@
+package list
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSortOrders(t *testing.T) {
+	dir := fixture(t, map[string]string{"a": "1", "b": "123", "c": "12", "dir/": ""})
+	touch(t, filepath.Join(dir, "a"), fixtureTime.Add(3*time.Hour))
+	touch(t, filepath.Join(dir, "c"), fixtureTime.Add(2*time.Hour))
+	touch(t, filepath.Join(dir, "b"), fixtureTime.Add(time.Hour))
+
+	tests := []struct {
+		name string
+		set  func(opts *Options)
+		want string
+	}{
+		{"default", func(opts *Options) {}, "dir a b c"},
+		{"name", func(opts *Options) { opts.Sort = "name" }, "a b c dir"},
+		{"size", func(opts *Options) { opts.Sort = "size" }, "b c a dir"},
+		{"time", func(opts *Options) { opts.Sort = "time" }, "a c b dir"},
+		{"reverse", func(opts *Options) { opts.Reverse = true }, "c b a dir"},
+		{"reverse size", func(opts *Options) { opts.Sort, opts.Reverse = "size", true }, "dir a c b"},
+		{"reverse directories first", func(opts *Options) { opts.Reverse, opts.GroupDirectoriesFirst = true, true }, "dir c b a"},
+		{"size directories first", func(opts *Options) { opts.Sort, opts.GroupDirectoriesFirst = "size", true }, "dir b c a"},
+	}
+
+	for _, test := range tests {
+		if got := strings.Join(strings.Fields(render(t, dir, test.set)), " "); got != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestSortNone(t *testing.T) {
+	entries := map[string]string{}
+
+	for i := 0; i < 50; i++ {
+		entries["file"+strconv.Itoa(i)] = ""
+	}
+
+	dir := fixture(t, entries)
+	files, err := readDir(dir)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var readOrder, reversed []string
+
+	for i, file := range files {
+		readOrder = append(readOrder, file.Name())
+		reversed = append(reversed, files[len(files)-1-i].Name())
+	}
+
+	tests := []struct {
+		reverse bool
+		want    []string
+	}{
+		{false, readOrder},
+		{true, reversed},
+	}
+
+	for _, test := range tests {
+		out := render(t, dir, func(opts *Options) { opts.Sort, opts.Reverse, opts.GridThreshold = "none", test.reverse, len(files) })
+
+		if strings.Join(lines(out), " ") != strings.Join(test.want, " ") {
+			t.Errorf("reverse %v: got %q, want the read order %q", test.reverse, lines(out), test.want)
+		}
+	}
+}
+
+func TestSortLinkTarget(t *testing.T) {
+	dir := fixture(t, map[string]string{"x-target": "", "y-target": "", "z-target": "", "plain": ""})
+	symlink(t, dir, "z-target", "a-link")
+	symlink(t, dir, "x-target", "b-link")
+	symlink(t, dir, "y-target", "c-link")
+
+	out := render(t, dir, func(opts *Options) { opts.Sort = "link-target" })
+	want := "plain x-target y-target z-target b-link c-link a-link"
+
+	if got := strings.Join(strings.Fields(out), " "); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestGroupByExtension(t *testing.T) {
+	dir := fixture(t, map[string]string{"b.go": "", "a.md": "", "a.go": "", "Makefile": "", "sub/": ""})
+
+	tests := []struct {
+		name string
+		set  func(opts *Options)
+		want []string
+	}{
+		{"grouped", func(opts *Options) {}, []string{"sub", "no extension", "Makefile", "*.go", "a.go", "b.go", "*.md", "a.md"}},
+		{"reversed", func(opts *Options) { opts.Reverse = true }, []string{"*.md", "a.md", "*.go", "b.go", "a.go", "no extension", "Makefile", "sub"}},
+		{"reversed directories first", func(opts *Options) { opts.Reverse, opts.GroupDirectoriesFirst = true, true }, []string{"sub", "*.md", "a.md", "*.go", "b.go", "a.go", "no extension", "Makefile"}},
+	}
+
+	for _, test := range tests {
+		out := render(t, dir, func(opts *Options) {
+			opts.Long, opts.GroupByExtension = true, true
+			test.set(opts)
+		})
+
+		var got []string
+
+		for _, line := range lines(out) {
+			fields := strings.Fields(line)
+
+			if strings.HasPrefix(line, "*.") || line == "no extension" {
+				got = append(got, line)
+			} else {
+				got = append(got, fields[len(fields)-1])
+			}
+		}
+
+		if strings.Join(got, "|") != strings.Join(test.want, "|") {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestGroupByDay(t *testing.T) {
+	dir := fixture(t, map[string]string{"first": "", "second": "", "third": ""})
+	touch(t, filepath.Join(dir, "second"), fixtureTime.Add(time.Hour))
+	touch(t, filepath.Join(dir, "third"), fixtureTime.Add(-48*time.Hour))
+
+	out := render(t, dir, func(opts *Options) { opts.Long, opts.GroupByDay = true, true })
+	var headings, names []string
+
+	for _, line := range lines(out) {
+		if fields := strings.Fields(line); strings.HasSuffix(line, " 2020") {
+			headings = append(headings, line)
+		} else {
+			names = append(names, fields[len(fields)-1])
+		}
+	}
+
+	if want := "Sunday 1 March 2020|Friday 28 February 2020"; strings.Join(headings, "|") != want {
+		t.Errorf("got headings %q, want %q", headings, want)
+	}
+
+	if want := "second first third"; strings.Join(names, " ") != want {
+		t.Errorf("got %q, want %q", names, want)
+	}
+}
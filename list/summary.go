@@ -0,0 +1,164 @@
+package list
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// A summary holds the aggregate numbers of a listing. Only regular files count towards
+// the size, directories, symlinks and other entries like pipes are only counted. With
+// --symlinks-as-files symlinks are counted and sized like regular files instead.
+type summary struct {
+	Files       int                          `json:"files"`
+	Directories int                          `json:"directories"`
+	Symlinks    int                          `json:"symlinks"`
+	Other       int                          `json:"other"`
+	Size        int64                        `json:"size"`
+	ByExtension map[string]*extensionSummary `json:"byExtension"`
+}
+
+// An extensionSummary holds the number and combined size of the regular files sharing
+// an extension.
+type extensionSummary struct {
+	Files int   `json:"files"`
+	Size  int64 `json:"size"`
+}
+
+func summarize(files []os.FileInfo, symlinksAsFiles bool) summary {
+	total := summary{ByExtension: map[string]*extensionSummary{}}
+
+	for _, file := range files {
+		isSymlink := file.Mode()&os.ModeSymlink != 0
+
+		if file.IsDir() {
+			total.Directories++
+			continue
+		} else if isSymlink && !symlinksAsFiles {
+			total.Symlinks++
+			continue
+		} else if !file.Mode().IsRegular() && !isSymlink {
+			total.Other++
+			continue
+		}
+
+		total.Files++
+		total.Size += file.Size()
+
+		extension := filepath.Ext(file.Name())
+
+		if total.ByExtension[extension] == nil {
+			total.ByExtension[extension] = &extensionSummary{}
+		}
+
+		total.ByExtension[extension].Files++
+		total.ByExtension[extension].Size += file.Size()
+	}
+
+	return total
+}
+
+// Adds the numbers of another summary to this one.
+func (s *summary) add(other summary) {
+	s.Files += other.Files
+	s.Directories += other.Directories
+	s.Symlinks += other.Symlinks
+	s.Other += other.Other
+	s.Size += other.Size
+
+	for extension, numbers := range other.ByExtension {
+		if s.ByExtension[extension] == nil {
+			s.ByExtension[extension] = &extensionSummary{}
+		}
+
+		s.ByExtension[extension].Files += numbers.Files
+		s.ByExtension[extension].Size += numbers.Size
+	}
+}
+
+// Returns the summary of the files passing the filters in the whole tree below the
+// directory, at most --depth levels deep. Every directory is descended into, also the
+// ones filtered out, like in --flat. Hidden entries only count with --all, as they are
+// not listed otherwise.
+func (l *Lister) summarizeTree(path string, depth int, filters []filter, opts Options) summary {
+	total := summary{ByExtension: map[string]*extensionSummary{}}
+	files, err := readDir(path)
+
+	if err != nil {
+		l.warn(err)
+		return total
+	}
+
+	files = removePruned(removeHidden(files, opts), opts)
+	total.add(summarize(filterFiles(files, filters), opts.SymlinksAsFiles))
+
+	for _, file := range files {
+		if descendInto(file, opts) && depth != 1 {
+			total.add(l.summarizeTree(filepath.Join(path, file.Name()), depth-1, filters, opts))
+		}
+	}
+
+	return total
+}
+
+// Prints the grand total of the files in the tree below the directory.
+func (l *Lister) printRecursiveTotal(path string, filters []filter, opts Options) {
+	total := l.summarizeTree(path, opts.Depth, filters, opts)
+
+	fmt.Fprintf(l.Output, "%d files, %d directories, ", total.Files, total.Directories)
+
+	if total.Symlinks > 0 {
+		fmt.Fprintf(l.Output, "%d symlinks, ", total.Symlinks)
+	}
+
+	colorFileSize.Fprint(l.Output, formatSize(total.Size, opts))
+	fmt.Fprintln(l.Output, " total")
+}
+
+// Prints the number of files and directories listed and their combined size. The
+// directories only add to the size with --recursive, as the size of everything below
+// them.
+func printTotal(w io.Writer, files []os.FileInfo, path string, opts Options) {
+	total := summarize(files, opts.SymlinksAsFiles)
+
+	if opts.Recursive {
+		for _, file := range files {
+			if file.IsDir() {
+				size, _ := diskUsage(filepath.Join(path, file.Name()))
+				total.Size += size
+			}
+		}
+	}
+
+	fmt.Fprintf(w, "%d files, %d dirs, ", total.Files, total.Directories)
+
+	if total.Symlinks > 0 {
+		fmt.Fprintf(w, "%d symlinks, ", total.Symlinks)
+	}
+
+	colorFileSize.Fprint(w, formatSize(total.Size, opts))
+	fmt.Fprintln(w, " total")
+}
+
+// Returns a one line hint for --suggest on what to look at next, based on what was
+// left out of the listing and what is in it. It is empty when there is nothing to hint.
+func suggestion(files []os.FileInfo, hidden int, opts Options) string {
+	if hidden > 0 {
+		return fmt.Sprintf("%d hidden entries — use -a to show them", hidden)
+	}
+
+	var largest os.FileInfo
+
+	for _, file := range files {
+		if file.Mode().IsRegular() && (largest == nil || file.Size() > largest.Size()) {
+			largest = file
+		}
+	}
+
+	if largest != nil && largest.Size() > 0 && opts.Sort != "size" {
+		return fmt.Sprintf("largest file is %s (%s) — run gut --sort size to see more", largest.Name(), formatSize(largest.Size(), opts))
+	}
+
+	return ""
+}
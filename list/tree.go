@@ -0,0 +1,103 @@
+package list
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// A treeNode is an entry of the tree with the entries below it that are shown. Only
+// directories that were descended into have children.
+type treeNode struct {
+	name     string
+	file     os.FileInfo
+	expanded bool
+	children []*treeNode
+}
+
+// Prints the directory and everything below it as a tree, at most --depth levels deep.
+// With filters only the matching entries are shown, along with the directories that
+// lead to them.
+func (l *Lister) outputTree(path string, filters []filter, opts Options) error {
+	files, err := readDir(path)
+
+	if err != nil {
+		return err
+	}
+
+	colorPermDir.Fprintln(l.Output, path)
+	l.printTreeLevel(l.buildTreeLevel(path, files, filters, opts.Depth, opts), "", opts)
+
+	return nil
+}
+
+// Returns the nodes for the entries of one directory, descending into the
+// subdirectories as it goes. The whole level is built before it is printed, as the
+// branch lines depend on which of the entries are left after filtering.
+func (l *Lister) buildTreeLevel(path string, files []os.FileInfo, filters []filter, depth int, opts Options) []*treeNode {
+	var nodes []*treeNode
+
+	files = removePruned(removeHidden(files, opts), opts)
+	sortFiles(files, path, opts)
+
+	for _, file := range files {
+		node := &treeNode{name: file.Name(), file: file}
+
+		// Symlinked directories are not followed as the entries come from Lstat
+		if file.IsDir() && depth != 1 && descendInto(file, opts) {
+			fullPath := filepath.Join(path, file.Name())
+			children, err := readDir(fullPath)
+
+			if err != nil {
+				l.warn(err)
+			} else {
+				node.expanded = true
+				node.children = l.buildTreeLevel(fullPath, children, filters, depth-1, opts)
+			}
+		}
+
+		if len(node.children) > 0 || keepFile(file, filters) {
+			nodes = append(nodes, node)
+		}
+	}
+
+	return nodes
+}
+
+// Prints the nodes of one level prefixed with the branch lines of their ancestors.
+func (l *Lister) printTreeLevel(nodes []*treeNode, prefix string, opts Options) {
+	l.listed += len(nodes)
+
+	for i, node := range nodes {
+		connector, indent := "├── ", "│   "
+
+		if i == len(nodes)-1 {
+			connector, indent = "└── ", "    "
+		}
+
+		if !node.file.IsDir() {
+			fmt.Fprintln(l.Output, prefix+connector+treeName(node))
+			continue
+		}
+
+		// Merge chains of directories that hold nothing but a single directory
+		name := node.name
+
+		for opts.Collapse && len(node.children) == 1 && node.children[0].expanded {
+			node = node.children[0]
+			name += "/" + node.name
+		}
+
+		fmt.Fprintln(l.Output, prefix+connector+colorPermDir.Sprint(name))
+		l.printTreeLevel(node.children, prefix+indent, opts)
+	}
+}
+
+// Returns the name of a file in the tree, coloured for symlinks.
+func treeName(node *treeNode) string {
+	if node.file.Mode()&os.ModeSymlink != 0 {
+		return colorSymlinkDest.Sprint(node.name)
+	}
+
+	return node.name
+}
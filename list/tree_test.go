@@ -0,0 +1,87 @@
+package list
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTree(t *testing.T) {
+	dir := fixture(t, map[string]string{
+		"a/b/c/file": "",
+		"x/match.go": "",
+		"other.txt":  "",
+		".hidden/f":  "",
+	})
+
+	tests := []struct {
+		name string
+		set  func(opts *Options)
+		want []string
+	}{
+		{"whole tree", func(opts *Options) {}, []string{
+			"├── a",
+			"│   └── b",
+			"│       └── c",
+			"│           └── file",
+			"├── x",
+			"│   └── match.go",
+			"└── other.txt",
+		}},
+		{"depth", func(opts *Options) { opts.Depth = 2 }, []string{
+			"├── a",
+			"│   └── b",
+			"├── x",
+			"│   └── match.go",
+			"└── other.txt",
+		}},
+		{"only the ancestors of matches", func(opts *Options) { opts.Regexp = `\.go$` }, []string{
+			"└── x",
+			"    └── match.go",
+		}},
+		{"collapse", func(opts *Options) { opts.Collapse = true }, []string{
+			"├── a/b/c",
+			"│   └── file",
+			"├── x",
+			"│   └── match.go",
+			"└── other.txt",
+		}},
+		{"hidden entries with all", func(opts *Options) { opts.All, opts.Regexp = true, "^[.f]" }, []string{
+			"├── .hidden",
+			"└── a",
+			"    └── b",
+			"        └── c",
+			"            └── file",
+		}},
+		{"recurse into hidden", func(opts *Options) { opts.All, opts.RecurseIntoHidden, opts.Regexp = true, true, "^[.f]" }, []string{
+			"├── .hidden",
+			"│   └── f",
+			"└── a",
+			"    └── b",
+			"        └── c",
+			"            └── file",
+		}},
+	}
+
+	for _, test := range tests {
+		out := render(t, dir, func(opts *Options) {
+			opts.Tree = true
+			test.set(opts)
+		})
+
+		if got := lines(out); got[0] != dir || strings.Join(got[1:], "\n") != strings.Join(test.want, "\n") {
+			t.Errorf("%s: got\n%s\nwant\n%s\n%s", test.name, out, dir, strings.Join(test.want, "\n"))
+		}
+	}
+}
+
+func TestTreeDoesNotFollowSymlinks(t *testing.T) {
+	dir := fixture(t, map[string]string{"sub/file": ""})
+	symlink(t, dir, ".", "sub/loop")
+
+	out := render(t, dir, func(opts *Options) { opts.Tree = true })
+	want := []string{dir, "└── sub", "    ├── file", "    └── loop"}
+
+	if strings.Join(lines(out), "\n") != strings.Join(want, "\n") {
+		t.Errorf("got\n%s", out)
+	}
+}
@@ -1,297 +1,131 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
-	"io/ioutil"
-	"log"
+	"io"
 	"os"
-	"os/user"
-	"path/filepath"
-	"regexp"
 	"sort"
 	"strconv"
-	"strings"
-	"syscall"
-	"time"
 
-	"github.com/fatih/color"
-	"github.com/phayes/permbits"
+	"github.com/bcallaars/gut/list"
 	"github.com/urfave/cli"
 )
 
-const Spacer = "  "
-const KiB = 1024
-const MiB = KiB * KiB
-const GiB = MiB * MiB
-
-// Colour definitions
-var ColorModTime = color.New(color.FgBlue)
-var ColorPermDir = color.New(color.FgBlue, color.Bold)
-var ColorPermOther = color.New(color.FgCyan)
-var ColorPermRead = color.New(color.FgYellow)
-var ColorPermWrite = color.New(color.FgRed)
-var ColorPermExecute = color.New(color.FgGreen)
-var ColorPermNone = color.New(color.FgYellow)
-var ColorFileSize = color.New(color.FgGreen, color.Bold)
-var ColorOwner = color.New(color.FgYellow, color.Bold)
-var ColorSymlinkDest = color.New(color.FgCyan)
-var ColorSymlinkSource = color.New(color.FgMagenta, color.Bold)
-var ColorHeader = color.New(color.FgWhite, color.Underline)
-
 func main() {
 	setupApp()
 }
 
-type ByDir []os.FileInfo
-
-func (a ByDir) Len() int      { return len(a) }
-func (a ByDir) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
-func (a ByDir) Less(i, j int) bool {
-	if a[i].IsDir() && !a[j].IsDir() {
-		return true
-	} else if !a[i].IsDir() && a[j].IsDir() {
-		return false
-	} else {
-		return a[i].Name() < a[j].Name()
-	}
-}
-
-// Pads a string with whitespaces to the left with a specific size and returns a new string.
-func padLeft(size int, str string) string {
-	return strings.Repeat(" ", size) + str
-}
-
-// Prints a given time.
-func printDate(t time.Time) {
-	formattedTime := t.Format("2 Jan 15:04")
-
-	ColorModTime.Print(padLeft(12-len(formattedTime), formattedTime) + Spacer)
-}
-
-func printPermissions(file os.FileMode) {
-	permissions := permbits.FileMode(file)
-	// permissions.SetUserExecute(
-
-	if file.IsDir() {
-		ColorPermDir.Print("d")
-	} else if file.IsRegular() {
-		ColorPermNone.Print("-")
-	} else {
-		// We need to do more to find out this file mode
-		ColorPermOther.Print(strings.ToLower(string(file.String()[0])))
-	}
-
-	if permissions.UserRead() {
-		ColorPermRead.Print("r")
-	} else {
-		ColorPermNone.Print("-")
-	}
-
-	if permissions.UserWrite() {
-		ColorPermWrite.Print("w")
-	} else {
-		ColorPermNone.Print("-")
-	}
-
-	if permissions.UserExecute() {
-		ColorPermExecute.Print("x")
-	} else {
-		ColorPermNone.Print("-")
-	}
-
-	if permissions.GroupRead() {
-		ColorPermRead.Print("r")
-	} else {
-		ColorPermNone.Print("-")
-	}
-
-	if permissions.GroupWrite() {
-		ColorPermWrite.Print("w")
-	} else {
-		ColorPermNone.Print("-")
-	}
-
-	if permissions.GroupExecute() {
-		ColorPermExecute.Print("x")
-	} else {
-		ColorPermNone.Print("-")
-	}
-
-	if permissions.OtherRead() {
-		ColorPermRead.Print("r")
-	} else {
-		ColorPermNone.Print("-")
-	}
-
-	if permissions.OtherWrite() {
-		ColorPermWrite.Print("w")
-	} else {
-		ColorPermNone.Print("-")
-	}
-
-	if permissions.OtherExecute() {
-		ColorPermExecute.Print("x")
-	} else {
-		ColorPermNone.Print("-")
-	}
-
-	fmt.Print(Spacer)
-}
-
-func friendlySize(size int64) string {
-	if size < KiB {
-		return strconv.FormatInt(size, 10)
-	} else if size < MiB {
-		return strconv.FormatInt(size/KiB, 10) + "Ki"
-	} else if size < GiB {
-		return strconv.FormatInt(size/MiB, 10) + "Mi"
-	}
-
-	return string(size)
-}
-
-func printSize(file os.FileInfo) {
-	if file.IsDir() {
-		ColorPermNone.Print(padLeft(4, "-") + Spacer)
-	} else {
-		size := friendlySize(file.Size())
-		ColorFileSize.Print(padLeft(5-len(size), size), Spacer)
-	}
-}
-
-func printOwner(file os.FileInfo) {
-	owner, _ := user.LookupId(fmt.Sprint(file.Sys().(*syscall.Stat_t).Uid))
-	group, _ := user.LookupGroupId(fmt.Sprint(file.Sys().(*syscall.Stat_t).Uid))
-
-	ColorOwner.Print(owner.Username + " " + group.Name + Spacer)
-}
-
-func outputFiles(files []os.FileInfo, path string) {
-	boldBlue := color.New(color.FgBlue, color.Bold)
-
-	for _, file := range files {
-		printPermissions(file.Mode())
-		printSize(file)
-		printOwner(file)
-		printDate(file.ModTime())
-
-		if file.IsDir() {
-			boldBlue.Print(file.Name())
-		} else {
-			if file.Mode()&os.ModeSymlink != 0 {
-				// Follow the symlink
-				fullFilePath := filepath.Join(path, file.Name())
-				followedPath, err := filepath.EvalSymlinks(fullFilePath)
-
-				if err != nil {
-					fmt.Print(file.Name() + " → [unknown]")
-				} else {
-					ColorSymlinkDest.Print(file.Name())
-					fmt.Print(" → ")
-					ColorSymlinkSource.Print(followedPath)
-				}
-			} else {
-				fmt.Print(file.Name())
-			}
-		}
-
-		fmt.Println()
-	}
-}
-
-func outputHeader() {
-	ColorHeader.Print("Permissions")
-
-	fmt.Print(Spacer)
-	ColorHeader.Print("Size")
-
-	fmt.Print(Spacer)
-	ColorHeader.Print("User")
-
-	fmt.Print(Spacer)
-	ColorHeader.Print("Group")
-
-	ColorHeader.Print("Date Modified")
-
-	fmt.Print(Spacer)
-	ColorHeader.Print("Name")
-
-	fmt.Println()
-}
-
 func setupApp() {
 	app := cli.NewApp()
 	app.Name = "gut"
 	app.Version = "0.0.1"
 	app.Usage = "ls replacement written in go"
 
-	app.Flags = []cli.Flag{
-		cli.StringFlag{
-			Name:  "regexp, x",
-			Value: "",
-			Usage: "Regular expression string to search for files and directories.",
-		},
+	// Free up -h for human readable sizes like ls does
+	cli.HelpFlag = cli.BoolFlag{
+		Name:  "help",
+		Usage: "show help",
 	}
 
-	app.Action = func(c *cli.Context) error {
-		// Default path is the current directory
-		path := "./"
+	app.Flags = append(list.Flags,
+		cli.BoolFlag{
+			Name:  "paths-sorted",
+			Usage: "List the paths given in sorted order instead of the order they were given in.",
+		},
+		cli.BoolFlag{
+			Name:  "fail-if-empty",
+			Usage: "Exit with code 1 when no entries are listed, like when none match the filters.",
+		},
+		cli.BoolFlag{
+			Name:  "pager",
+			Usage: "Show the listing through $PAGER, less -R by default, also when it fits on the terminal.",
+		},
+		cli.BoolFlag{
+			Name:  "no-pager",
+			Usage: "Never show the listing through $PAGER, even when it does not fit on the terminal.",
+		},
+		cli.BoolFlag{
+			Name:  "ignore-errors",
+			Usage: "Always exit with code 0, reporting recoverable errors as warnings only.",
+		},
+	)
 
-		// This should check if the last value is a valid path without a flag
-		if len(os.Args) > 1 {
-			path = os.Args[len(os.Args)-1]
-		}
+	appFlags = app.Flags
 
-		clearPath, err := filepath.Abs(path)
+	app.Action = func(c *cli.Context) error {
+		opts := list.NewOptions(c, commandLineFlags(os.Args[1:]))
+		opts.Width = outputWidth()
 
-		if err != nil {
-			// The path does not exist
-			log.Fatal(err)
-			return err
+		// Colours only end up as noise in a file or another program, which stdout is
+		// still known to be when the listing is held back for the pager
+		if opts.Color == "auto" {
+			opts.Color = "never"
+
+			if os.Getenv("NO_COLOR") == "" && list.IsTerminal(os.Stdout) {
+				opts.Color = "always"
+			}
 		}
 
-		files, err := ioutil.ReadDir(clearPath)
+		// Only output for a terminal is paged, so it is held back until it is known
+		// whether it fits on the screen
+		var output io.Writer = os.Stdout
+		var buffered bytes.Buffer
 
-		if err != nil {
-			log.Fatal(err)
+		if list.IsTerminal(os.Stdout) && !c.Bool("no-pager") {
+			output = &buffered
+			defer flushPaged(&buffered, c.Bool("pager"))
 		}
 
-		sort.Sort(ByDir(files))
+		warnings := 0
+		lister := &list.Lister{Output: output, Log: os.Stderr, Warn: func(err error) {
+			warnings++
 
-		regex := c.String("regexp")
+			if !opts.Quiet {
+				fmt.Fprintln(os.Stderr, "gut:", err)
+			}
+		}}
 
-		if len(regex) > 0 {
-			files, err = filterFiles(files, regex)
+		paths := []string(c.Args())
 
-			if err != nil {
-				log.Fatal(err)
-				return err
-			}
+		// Default path is the current directory
+		if len(paths) == 0 {
+			paths = []string{"./"}
+		} else if c.Bool("paths-sorted") {
+			sort.Strings(paths)
+		}
+
+		// Returned rather than exiting right away, so the output held back for the
+		// pager is still shown
+		if err := lister.RenderAll(paths, opts); err != nil {
+			return cli.NewExitError("gut: "+err.Error(), 1)
 		}
 
-		// outputHeader()
-		outputFiles(files, clearPath)
+		if warnings > 0 && !c.Bool("ignore-errors") {
+			return cli.NewExitError("", 1)
+		} else if c.Bool("fail-if-empty") && lister.Listed() == 0 {
+			return cli.NewExitError("", 1)
+		}
 
 		return nil
 	}
 
-	app.Run(os.Args)
-}
-
-func filterFiles(files []os.FileInfo, regex string) ([]os.FileInfo, error) {
-	match, err := regexp.Compile(regex)
+	args, err := withDefaultArgs(os.Args)
 
 	if err != nil {
-		return nil, err
+		cli.HandleExitCoder(cli.NewExitError("gut: "+err.Error(), 1))
+		return
 	}
 
-	var filteredFiles []os.FileInfo
+	app.Run(append([]string{args[0]}, flagsFirst(args[1:])...))
+}
 
-	for i := 0; i < len(files); i++ {
-		if match.MatchString(files[i].Name()) {
-			filteredFiles = append(filteredFiles, files[i])
-		}
+// Returns the width to fit the listing in, taken from $COLUMNS when it is set and from
+// the terminal otherwise. It is 0 when neither gives one.
+func outputWidth() int {
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
 	}
 
-	return filteredFiles, nil
+	return terminalWidth()
 }
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/urfave/cli"
+)
+
+// Runs gut with the arguments and returns what it printed to stdout and stderr and the
+// code it exited with. The arguments in $GUT_DEFAULT_ARGS go in front of them.
+func runGut(t *testing.T, args ...string) (stdout string, stderr string, code int) {
+	t.Helper()
+	t.Setenv("COLUMNS", "80")
+
+	outFile, err := os.Create(filepath.Join(t.TempDir(), "stdout"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errFile, err := os.Create(filepath.Join(t.TempDir(), "stderr"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer func(args []string, stdout *os.File, stderr *os.File) {
+		os.Args, os.Stdout, os.Stderr = args, stdout, stderr
+		cli.OsExiter, cli.ErrWriter = os.Exit, os.Stderr
+	}(os.Args, os.Stdout, os.Stderr)
+
+	os.Args, os.Stdout, os.Stderr = append([]string{"gut"}, args...), outFile, errFile
+	cli.OsExiter, cli.ErrWriter = func(exitCode int) { code = exitCode }, errFile
+
+	setupApp()
+
+	out, err := ioutil.ReadFile(outFile.Name())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errOut, err := ioutil.ReadFile(errFile.Name())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(out), string(errOut), code
+}
+
+func TestExitCodes(t *testing.T) {
+	t.Setenv("GUT_DEFAULT_ARGS", "")
+	dir := t.TempDir()
+
+	for _, name := range []string{"b", "a"} {
+		if err := os.Mkdir(filepath.Join(dir, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := ioutil.WriteFile(filepath.Join(dir, name, "file"), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	a, b, missing := filepath.Join(dir, "a"), filepath.Join(dir, "b"), filepath.Join(dir, "missing")
+
+	tests := []struct {
+		name   string
+		args   []string
+		stdout string
+		stderr string
+		code   int
+	}{
+		{"listing", []string{a}, "file\n", "", 0},
+		{"flags after the path", []string{a, "-x", "nothing"}, "", "", 0},
+		{"bundled flags", []string{"-ax", "file", a}, "file\n", "", 0},
+		{"in the given order", []string{b, a}, b + ":\nfile\n\n" + a + ":\nfile\n", "", 0},
+		{"paths sorted", []string{"--paths-sorted", b, a}, a + ":\nfile\n\n" + b + ":\nfile\n", "", 0},
+		{"missing path", []string{missing}, "", "gut: lstat " + missing, 1},
+		{"one missing path", []string{a, missing}, a + ":\nfile\n", "gut: lstat " + missing, 1},
+		{"files first", []string{a, filepath.Join(b, "file")}, filepath.Join(b, "file") + "\n\n" + a + ":\nfile\n", "", 0},
+		{"ignore errors", []string{"--ignore-errors", b, missing}, b + ":\nfile\n", "gut: lstat " + missing, 0},
+		{"quiet", []string{"--quiet", a, missing}, a + ":\nfile\n", "", 1},
+		{"fail if empty", []string{"--fail-if-empty", "-x", "nothing", a}, "", "", 1},
+		{"fail if empty with entries", []string{"--fail-if-empty", a}, "file\n", "", 0},
+		{"fail if empty with the dot entries", []string{"--fail-if-empty", "-a", "-x", "zzz", a}, "", "", 1},
+		{"bad option", []string{"--sort", "sideways", a}, "", "gut: unknown sort order: sideways", 1},
+	}
+
+	for _, test := range tests {
+		stdout, stderr, code := runGut(t, test.args...)
+
+		if stdout != test.stdout || !strings.HasPrefix(stderr, test.stderr) || (test.stderr == "") != (stderr == "") || code != test.code {
+			t.Errorf("%s: got %q, %q and exit code %d, want %q, %q and %d", test.name, stdout, stderr, code, test.stdout, test.stderr, test.code)
+		}
+	}
+}
+
+func TestDefaultArgs(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, name := range []string{".hidden", "file"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		defaults string
+		args     []string
+		want     string
+	}{
+		{"-a -r", []string{dir}, ". .. file .hidden"},
+		{"-a -r", []string{dir, "-x", "^f"}, "file"},
+		{"-a", []string{"--reverse", dir}, ". .. file .hidden"},
+	}
+
+	for _, test := range tests {
+		t.Setenv("GUT_DEFAULT_ARGS", test.defaults)
+
+		if stdout, _, _ := runGut(t, test.args...); strings.Join(strings.Fields(stdout), " ") != test.want {
+			t.Errorf("%q with %q: got %q, want %q", test.defaults, test.args, stdout, test.want)
+		}
+	}
+}
+
+func TestBadDefaultArgs(t *testing.T) {
+	t.Setenv("GUT_DEFAULT_ARGS", "--exclude 'open")
+
+	stdout, stderr, code := runGut(t, t.TempDir())
+
+	if stdout != "" || stderr != "gut: GUT_DEFAULT_ARGS: unterminated quote or escape\n" || code != 1 {
+		t.Errorf("got %q, %q and exit code %d", stdout, stderr, code)
+	}
+}
+
+func TestCurrentDirectory(t *testing.T) {
+	t.Setenv("GUT_DEFAULT_ARGS", "")
+	dir := t.TempDir()
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "file"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	wd, err := os.Getwd()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.Chdir(wd)
+
+	for _, args := range [][]string{nil, {"."}, {"-x", "f"}, {"-x", "f", "."}} {
+		if stdout, stderr, code := runGut(t, args...); stdout != "file\n" || stderr != "" || code != 0 {
+			t.Errorf("gut %s: got %q, %q and exit code %d", strings.Join(args, " "), stdout, stderr, code)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+
+	"github.com/bcallaars/gut/list"
+)
+
+// DefaultPager is run when $PAGER is not set. The -R keeps the colours.
+const DefaultPager = "less -R"
+
+// Writes the held back output to the pager, with --pager or when it does not fit on
+// the terminal, and straight to stdout otherwise or when the pager cannot be started.
+func flushPaged(buffered *bytes.Buffer, force bool) {
+	if !force && (terminalHeight() == 0 || bytes.Count(buffered.Bytes(), []byte("\n")) < terminalHeight()) {
+		os.Stdout.Write(buffered.Bytes())
+		return
+	}
+
+	command := os.Getenv("PAGER")
+
+	if command == "" {
+		command = DefaultPager
+	}
+
+	args, err := list.SplitArgs(command)
+
+	if err != nil || len(args) == 0 {
+		os.Stdout.Write(buffered.Bytes())
+		return
+	}
+
+	pager := exec.Command(args[0], args[1:]...)
+	pager.Stdin = bytes.NewReader(buffered.Bytes())
+	pager.Stdout = os.Stdout
+	pager.Stderr = os.Stderr
+
+	if err := pager.Start(); err != nil {
+		os.Stdout.Write(buffered.Bytes())
+		return
+	}
+
+	// Quitting the pager before the end is no reason to fail
+	pager.Wait()
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Returns what flushPaged wrote to stdout for the output.
+func flushed(t *testing.T, output string, force bool) string {
+	t.Helper()
+
+	file, err := os.Create(filepath.Join(t.TempDir(), "stdout"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer func(stdout *os.File) { os.Stdout = stdout }(os.Stdout)
+	os.Stdout = file
+
+	flushPaged(bytes.NewBufferString(output), force)
+
+	written, err := ioutil.ReadFile(file.Name())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(written)
+}
+
+func TestFlushPaged(t *testing.T) {
+	tests := []struct {
+		name  string
+		pager string
+		force bool
+		want  string
+	}{
+		{"fits on the screen", "sed s/^/paged:/", false, "a\nb\n"},
+		{"forced", "sed s/^/paged:/", true, "paged:a\npaged:b\n"},
+		{"pager that does not exist", "no-such-pager", true, "a\nb\n"},
+		{"unterminated quote", "'less", true, "a\nb\n"},
+	}
+
+	for _, test := range tests {
+		t.Setenv("PAGER", test.pager)
+
+		if got := flushed(t, "a\nb\n", test.force); got != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
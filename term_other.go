@@ -0,0 +1,14 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd
+
+package main
+
+// The terminal size is only asked for on the Unix systems, elsewhere it is taken from
+// $COLUMNS.
+func terminalWidth() int {
+	return 0
+}
+
+// Without the height long listings are only paged with --pager.
+func terminalHeight() int {
+	return 0
+}
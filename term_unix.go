@@ -0,0 +1,33 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd
+
+package main
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// Returns the number of columns of the terminal on stdout, 0 when it is not a terminal.
+func terminalWidth() int {
+	_, cols := terminalSize()
+
+	return cols
+}
+
+// Returns the number of lines of the terminal on stdout, 0 when it is not a terminal.
+func terminalHeight() int {
+	rows, _ := terminalSize()
+
+	return rows
+}
+
+func terminalSize() (rows int, cols int) {
+	size, err := unix.IoctlGetWinsize(int(os.Stdout.Fd()), unix.TIOCGWINSZ)
+
+	if err != nil {
+		return 0, 0
+	}
+
+	return int(size.Row), int(size.Col)
+}